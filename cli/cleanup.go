@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"health/routes/client/userRole/usecase"
+	"health/server"
+)
+
+const cleanupUsage = "usage: cleanup user-roles [-dry-run]"
+
+func runCleanup(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, cleanupUsage)
+	}
+
+	flags := flag.NewFlagSet("cleanup "+args[0], flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only count orphans, do not delete them")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "user-roles":
+		return cleanupUserRoles(*dryRun)
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, cleanupUsage)
+}
+
+// cleanupUserRoles удаляет записи user.roles, на которые не ссылается ни один юзер
+func cleanupUserRoles(dryRun bool) error {
+	store := server.InitStorage()
+
+	// * Письма не отправляются и кеша юзеров в CLI нет, mailer и auth не нужны
+	uc := usecase.NewUseCase(
+		store.UserRoles,
+		store.Roles,
+		store.Users,
+		nil,
+		nil,
+	)
+
+	count, err := uc.CleanupOrphans(context.Background(), dryRun)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("%d orphaned user roles found\n", count)
+		return nil
+	}
+	fmt.Printf("%d orphaned user roles deleted\n", count)
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Run выполняет подкоманду приложения, например `app keys rotate`
+func Run(args []string) error {
+	switch args[0] {
+	case "keys":
+		return runKeys(args[1:])
+	case "migrate":
+		return runMigrate(args[1:])
+	case "cleanup":
+		return runCleanup(args[1:])
+	case "storage":
+		return runStorage(args[1:])
+	case "i18n":
+		return runI18n(args[1:])
+	case "openapi":
+		return runOpenAPI(args[1:])
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+}
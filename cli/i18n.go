@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"health/services/i18n"
+	"health/shared/errs"
+)
+
+const i18nUsage = "usage: i18n check"
+
+var ErrMissingTranslations = errors.New("missing translations")
+
+func runI18n(args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, i18nUsage)
+	}
+
+	return checkTranslations()
+}
+
+// checkTranslations — у каждого кода ошибки есть текст в en, а в остальных словарях есть все ключи en
+func checkTranslations() error {
+	missing := 0
+
+	for _, code := range errs.Codes() {
+		if _, ok := i18n.Lookup(i18n.EN, i18n.ErrorKey(code)); !ok {
+			fmt.Printf("en: no message for error code %s\n", code)
+			missing++
+		}
+	}
+
+	for _, locale := range i18n.Supported() {
+		for _, key := range i18n.Missing(locale) {
+			fmt.Printf("%s: no translation for %s\n", locale, key)
+			missing++
+		}
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("%w: %d", ErrMissingTranslations, missing)
+	}
+	fmt.Println("ok   all translations are in place")
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"health/services/jwk"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+const keysUsage = "usage: keys generate|rotate|list [-dir ./keys] [-alg EdDSA|RS256]"
+
+func runKeys(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, keysUsage)
+	}
+
+	flags := flag.NewFlagSet("keys "+args[0], flag.ContinueOnError)
+	dir := flags.String("dir", viper.GetString("auth.keys.dir"), "directory with signing keys")
+	alg := flags.String("alg", jwk.AlgEdDSA, "key algorithm: EdDSA or RS256")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "generate":
+		// * Новый ключ только публикуется в JWKS, подписывать им начнем после rotate.
+		// Первый ключ в пустой папке сразу становится активным, иначе сервер не стартует.
+		_, activeErr := jwk.ReadActiveKID(*dir)
+
+		key, err := generateKey(*dir, *alg)
+		if err != nil {
+			return err
+		}
+		if errors.Is(activeErr, os.ErrNotExist) {
+			if err := jwk.WriteActiveKID(*dir, key.ID); err != nil {
+				return err
+			}
+		}
+		fmt.Println(key.ID)
+
+		return nil
+	case "rotate":
+		key, err := generateKey(*dir, *alg)
+		if err != nil {
+			return err
+		}
+		if err := jwk.WriteActiveKID(*dir, key.ID); err != nil {
+			return err
+		}
+		fmt.Printf("%s is active now, previous keys stay valid for verification\n", key.ID)
+
+		return nil
+	case "list":
+		return listKeys(*dir)
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, keysUsage)
+}
+
+func generateKey(dir string, alg string) (*jwk.Key, error) {
+	key, err := jwk.GenerateKey(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := jwk.WriteKey(dir, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func listKeys(dir string) error {
+	keys, err := jwk.ReadKeys(dir)
+	if err != nil {
+		return err
+	}
+
+	activeKID, _ := jwk.ReadActiveKID(dir)
+
+	for _, id := range jwk.SortedIDs(keys) {
+		key := keys[id]
+
+		status := "verify-only"
+		if key.Private != nil {
+			status = "signing"
+		}
+		if key.ID == activeKID {
+			status = "active"
+		}
+
+		fmt.Printf("%s\t%s\t%s\n", key.ID, key.Algorithm, status)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"health/migrations"
+	"health/server"
+	"health/services/migration"
+	"health/storage"
+
+	"github.com/spf13/viper"
+)
+
+const migrateUsage = "usage: migrate up|status"
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, migrateUsage)
+	}
+
+	var migrator migration.Runner
+	switch driver := viper.GetString("db.driver"); driver {
+	case "", storage.DriverMongo:
+		migrator = migration.NewMigrator(server.InitDB(), migrations.All)
+	case storage.DriverPostgres:
+		migrator = migration.NewSQLMigrator(server.InitPostgres(), migrations.Postgres)
+	default:
+		return fmt.Errorf("no migrations for db.driver %s", driver)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(context.Background())
+		for _, m := range applied {
+			fmt.Printf("%d %s applied\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+
+		return nil
+	case "status":
+		statuses, err := migrator.Status(context.Background())
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if !status.AppliedAt.IsZero() {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%d\t%s\t%s\n", status.Version, appliedAt, status.Name)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, migrateUsage)
+}
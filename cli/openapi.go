@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"health/routes"
+	"health/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+const openapiUsage = "usage: openapi check"
+
+var ErrSpecDrift = errors.New("openapi spec does not match routes")
+
+func runOpenAPI(args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, openapiUsage)
+	}
+
+	return checkOpenAPI()
+}
+
+// checkOpenAPI сверяет роуты всех модулей со спецификацией, сама сверка — routes.CheckSpec
+func checkOpenAPI() error {
+	gin.SetMode(gin.ReleaseMode)
+
+	problems, documented := routes.CheckSpec(func(c *gin.Context) { c.Next() }, server.DocsModules()...)
+	for _, problem := range problems {
+		fmt.Printf("FAIL %s\n", problem)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %d problems", ErrSpecDrift, len(problems))
+	}
+
+	fmt.Printf("ok   %d routes documented\n", documented)
+
+	return nil
+}
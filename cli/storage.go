@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"health/routes/client/auth"
+	"health/server"
+	"health/storage"
+	"health/storage/conformance"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+const storageUsage = "usage: storage check [-driver mongo|postgres|memory]"
+
+var (
+	ErrConformance       = errors.New("storage does not conform")
+	ErrCheckOnProduction = errors.New("storage check refuses to run with GO_ENV=production")
+	ErrStorageNotEmpty   = errors.New("storage check refuses to run against a database with users")
+)
+
+func runStorage(args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, storageUsage)
+	}
+
+	flags := flag.NewFlagSet("storage check", flag.ContinueOnError)
+	driver := flags.String("driver", "", "storage driver to check, db.driver by default")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *driver != "" {
+		viper.Set("db.driver", *driver)
+	}
+
+	return checkStorage()
+}
+
+// checkStorage прогоняет общий набор проверок поверх выбранного драйвера.
+// Проверки пишут в базу уникальные записи и удаляют их за собой,
+// поэтому гоняем их только на пустой базе и не с боевым конфигом.
+func checkStorage() error {
+	if os.Getenv("GO_ENV") == "production" {
+		return ErrCheckOnProduction
+	}
+
+	store := server.InitStorage()
+	if err := ensureEmptyStorage(store); err != nil {
+		return err
+	}
+
+	failed := false
+	for _, check := range conformance.Checks {
+		if err := check.Run(context.Background(), store); err != nil {
+			failed = true
+			fmt.Printf("FAIL %s: %v\n", check.Name, err)
+			continue
+		}
+		fmt.Printf("ok   %s\n", check.Name)
+	}
+
+	if failed {
+		return fmt.Errorf("%w: %s", ErrConformance, viper.GetString("db.driver"))
+	}
+
+	return nil
+}
+
+// ensureEmptyStorage — в базе с юзерами проверки могут задеть живые данные
+func ensureEmptyStorage(store *storage.Storage) error {
+	_, total, err := store.Users.ListUsers(context.Background(), &auth.UserFilter{Limit: 1})
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return fmt.Errorf("%w: %s has %d users", ErrStorageNotEmpty, viper.GetString("db.driver"), total)
+	}
+
+	return nil
+}
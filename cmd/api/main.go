@@ -1,9 +1,11 @@
 package main
 
 import (
+	"health/cli"
 	"health/configs"
 	"health/server"
 	"log"
+	"os"
 
 	"github.com/spf13/viper"
 )
@@ -12,6 +14,15 @@ func main() {
 	// Загружаем конфиги, они доступны через viper.Get(...)
 	configs.Init()
 
+	// Подкоманды, например `app keys rotate`
+	if len(os.Args) > 1 {
+		if err := cli.Run(os.Args[1:]); err != nil {
+			log.Fatalf("%s", err.Error())
+		}
+
+		return
+	}
+
 	app := server.InitApp()
 
 	if err := app.Run(viper.GetString("app.port")); err != nil {
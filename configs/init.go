@@ -14,27 +14,41 @@ const (
 	development string = "development"
 )
 
+// setEnv — пустая переменная из .env файла не затирает значение из json конфига
 func setEnv(configKey string, envName string) {
 	value, exist := os.LookupEnv(envName)
-	if exist {
+	if exist && value != "" {
 		viper.Set(configKey, value)
 	}
 }
 
 func setConfigsFromEnv() {
+	// set env for app
+	setEnv("app.client_url", "APP_CLIENT_URL")
+	setEnv("app.default_locale", "APP_DEFAULT_LOCALE")
+
 	// set env for db
+	setEnv("db.driver", "DB_DRIVER")
 	setEnv("db.uri", "DB_URI")
 	setEnv("db.name", "DB_NAME")
 	setEnv("db.options.user", "DB_USER")
 	setEnv("db.options.password", "DB_USER_PASSWORD")
+	setEnv("db.transactions", "DB_TRANSACTIONS")
+	setEnv("db.migrate_on_boot", "DB_MIGRATE_ON_BOOT")
+	setEnv("db.postgres.dsn", "DB_POSTGRES_DSN")
 
 	// set env for services
 	setEnv("services.email.SMTP_FROM", "SMTP_FROM")
 	setEnv("services.email.SMTP_PASSWORD", "SMTP_PASSWORD")
 
 	// set auth env
-	setEnv("auth.signing_key", "AUTH_SIGNING_KEY")
-	setEnv("auth.token_ttl", "AUTH_TOKEN_TTL")
+	setEnv("auth.login_mode", "AUTH_LOGIN_MODE")
+	setEnv("auth.bootstrap_admin_email", "AUTH_BOOTSTRAP_ADMIN_EMAIL")
+	setEnv("auth.keys.dir", "AUTH_KEYS_DIR")
+	setEnv("auth.keys.active_kid", "AUTH_KEYS_ACTIVE_KID")
+	setEnv("auth.keys.ephemeral", "AUTH_KEYS_EPHEMERAL")
+	setEnv("auth.access_token_ttl", "AUTH_ACCESS_TOKEN_TTL")
+	setEnv("auth.refresh_token_ttl", "AUTH_REFRESH_TOKEN_TTL")
 }
 
 func Init() {
@@ -7,7 +7,9 @@ require (
 	github.com/gin-gonic/gin v1.4.0
 	github.com/go-playground/validator v9.31.0+incompatible
 	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.10.9
 	github.com/spf13/viper v1.4.0
+	github.com/swaggo/files v1.0.1
 	go.mongodb.org/mongo-driver v1.1.2
 	golang.org/x/crypto v0.7.0
 )
@@ -37,7 +39,8 @@ require (
 	github.com/ugorji/go v1.1.4 // indirect
 	github.com/xdg/scram v0.0.0-20180814205039-7eeb5667e42c // indirect
 	github.com/xdg/stringprep v1.0.0 // indirect
-	golang.org/x/sync v0.0.0-20181221193216-37e7f081c4d4 // indirect
+	golang.org/x/net v0.8.0 // indirect
+	golang.org/x/sync v0.0.0-20220722155255-886fb9371eb4 // indirect
 	golang.org/x/sys v0.6.0 // indirect
 	golang.org/x/text v0.8.0 // indirect
 	gopkg.in/go-playground/validator.v8 v8.18.2 // indirect
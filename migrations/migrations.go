@@ -0,0 +1,12 @@
+package migrations
+
+import "health/services/migration"
+
+// All — все миграции схемы. Новая миграция получает следующий номер версии,
+// уже выпущенные миграции не меняем, вместо этого добавляем новую.
+var All = []migration.Migration{
+	{Version: 1, Name: "create users, roles and user roles indexes", Up: createIndexes},
+	{Version: 2, Name: "expire sessions and revoked tokens", Up: expireTokens},
+	{Version: 3, Name: "backfill user flags", Up: backfillUserFlags},
+	{Version: 4, Name: "store IIN as string with unique index", Up: iinToString},
+}
@@ -0,0 +1,12 @@
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed postgres/*.sql
+var postgresFiles embed.FS
+
+// Postgres — sql миграции для db.driver=postgres, уже выпущенные файлы не меняем
+var Postgres, _ = fs.Sub(postgresFiles, "postgres")
@@ -0,0 +1,101 @@
+-- Схема повторяет коллекции монги. Вложенные объекты юзера храним в jsonb,
+-- отдельными колонками только то, по чему фильтруем и сортируем.
+-- id — ObjectID в hex, чтобы id были одинаковыми на обоих хранилищах.
+
+CREATE TABLE users (
+    id                    TEXT PRIMARY KEY,
+
+    email                 TEXT        NOT NULL,
+    password              TEXT        NOT NULL DEFAULT '',
+    password_confirm      TEXT        NOT NULL DEFAULT '',
+    verified              BOOLEAN     NOT NULL DEFAULT FALSE,
+    verify_code           JSONB       NOT NULL DEFAULT '{}',
+    email_change          JSONB       NOT NULL DEFAULT '{}',
+    two_factor            JSONB       NOT NULL DEFAULT '{}',
+    sign_in_lock          JSONB       NOT NULL DEFAULT '{}',
+
+    suspended             BOOLEAN     NOT NULL DEFAULT FALSE,
+    suspended_at          TIMESTAMPTZ NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+
+    user_role_ids         TEXT[]      NOT NULL DEFAULT '{}',
+    roles_version         INTEGER     NOT NULL DEFAULT 0,
+
+    finished_registration BOOLEAN     NOT NULL DEFAULT FALSE,
+
+    iin                   BIGINT      NOT NULL DEFAULT 0,
+    name                  TEXT        NOT NULL DEFAULT '',
+    surname               TEXT        NOT NULL DEFAULT '',
+    birthday              TIMESTAMPTZ NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+    gender                INTEGER     NOT NULL DEFAULT 0,
+    address               JSONB       NOT NULL DEFAULT '{}',
+
+    created_at            TIMESTAMPTZ NOT NULL,
+    updated_at            TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX users_email_unique ON users (email);
+
+CREATE TABLE roles (
+    id          TEXT PRIMARY KEY,
+
+    is_default  BOOLEAN     NOT NULL DEFAULT FALSE,
+    name        TEXT        NOT NULL,
+    permissions TEXT[]      NOT NULL DEFAULT '{}',
+
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX roles_name_unique ON roles (name);
+
+-- Внешних ключей нет намеренно: как и в монге, порядок создания юзера и его ролей
+-- задает usecase, а сирот убирает `cleanup user-roles`
+CREATE TABLE user_roles (
+    id         TEXT PRIMARY KEY,
+
+    user_id    TEXT        NOT NULL,
+    role_id    TEXT        NOT NULL,
+    status     TEXT        NOT NULL,
+
+    decided_by TEXT,
+    decided_at TIMESTAMPTZ NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+    reason     TEXT        NOT NULL DEFAULT '',
+
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX user_roles_user_id_role_id ON user_roles (user_id, role_id);
+CREATE INDEX user_roles_role_id ON user_roles (role_id);
+CREATE INDEX user_roles_status_created_at ON user_roles (status, created_at);
+
+CREATE TABLE sessions (
+    id              TEXT PRIMARY KEY,
+
+    user_id         TEXT        NOT NULL,
+    family_id       TEXT        NOT NULL,
+    token_hash      TEXT        NOT NULL,
+    access_token_id TEXT        NOT NULL DEFAULT '',
+
+    rotated         BOOLEAN     NOT NULL DEFAULT FALSE,
+    revoked         BOOLEAN     NOT NULL DEFAULT FALSE,
+
+    expires_at      TIMESTAMPTZ NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX sessions_token_hash_unique ON sessions (token_hash);
+CREATE INDEX sessions_family_id ON sessions (family_id);
+CREATE INDEX sessions_user_id ON sessions (user_id);
+CREATE INDEX sessions_expires_at ON sessions (expires_at);
+
+CREATE TABLE revoked_tokens (
+    token_id   TEXT PRIMARY KEY,
+    user_id    TEXT        NOT NULL,
+
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX revoked_tokens_expires_at ON revoked_tokens (expires_at);
@@ -0,0 +1,11 @@
+-- ИИН хранился числом и терял ведущий ноль у родившихся в 2000-х.
+-- 0 (профиль не заполнен) становится пустой строкой, уникальны только заполненные ИИН.
+
+ALTER TABLE users ALTER COLUMN iin DROP DEFAULT;
+
+ALTER TABLE users ALTER COLUMN iin TYPE TEXT
+    USING CASE WHEN iin = 0 THEN '' ELSE lpad(iin::TEXT, 12, '0') END;
+
+ALTER TABLE users ALTER COLUMN iin SET DEFAULT '';
+
+CREATE UNIQUE INDEX users_iin_unique ON users (iin) WHERE iin <> '';
@@ -0,0 +1,3 @@
+-- Язык писем и ошибок API, выбранный юзером. Пустая строка — язык из Accept-Language.
+
+ALTER TABLE users ADD COLUMN locale TEXT NOT NULL DEFAULT '';
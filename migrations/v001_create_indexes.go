@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"context"
+	"health/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// createIndexes — уникальность email и имени роли держит база, а не проверка перед insert
+func createIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(models.UserCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetName("email_unique").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection(models.RoleCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetName("name_unique").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection(models.UserRoleCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			// * роли юзера и каскадное удаление. Не уникальный: старый RemoveRole оставлял сирот,
+			// * и у юзера могут быть две записи на одну роль, пока их не уберет `cleanup user-roles`
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "roleId", Value: 1}},
+			Options: options.Index().SetName("userId_roleId"),
+		},
+		{
+			// * проверка, что роль не используется, перед ее удалением
+			Keys:    bson.D{{Key: "roleId", Value: 1}},
+			Options: options.Index().SetName("roleId"),
+		},
+		{
+			// * список заявок админа
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("status_created_at"),
+		},
+	})
+
+	return err
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"context"
+	"health/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// backfillUserFlags — у юзеров, созданных до появления полей, их нет вовсе,
+// и фильтр админки suspended=false таких юзеров не находит
+func backfillUserFlags(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection(models.UserCollection)
+
+	_, err := users.UpdateMany(ctx,
+		bson.M{"suspended": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"suspended": false}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = users.UpdateMany(ctx,
+		bson.M{"rolesVersion": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"rolesVersion": 0}},
+	)
+
+	return err
+}
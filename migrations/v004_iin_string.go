@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"health/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// iinToString — ИИН хранился числом и терял ведущий ноль у родившихся в 2000-х.
+// Переводим в строку из 12 цифр, 0 (профиль не заполнен) — в пустую строку,
+// и вешаем уникальный индекс только на заполненные ИИН.
+// Если у двух юзеров уже один ИИН, индекс не создастся: дубли нужно разобрать руками и перезапустить.
+func iinToString(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection(models.UserCollection)
+
+	cursor, err := users.Find(ctx,
+		bson.M{"IIN": bson.M{"$type": "number"}},
+		options.Find().SetProjection(bson.M{"IIN": 1}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID  primitive.ObjectID `bson:"_id"`
+			IIN int64              `bson:"IIN"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+
+		iin := ""
+		if doc.IIN != 0 {
+			iin = fmt.Sprintf("%012d", doc.IIN)
+		}
+
+		_, err := users.UpdateOne(ctx,
+			bson.M{"_id": doc.ID},
+			bson.M{"$set": bson.M{"IIN": iin}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	_, err = users.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "IIN", Value: 1}},
+		Options: options.Index().
+			SetName("IIN_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"IIN": bson.M{"$gt": ""}}),
+	})
+
+	return err
+}
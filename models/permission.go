@@ -0,0 +1,31 @@
+package models
+
+// Permission — право на действие, роли несут набор прав
+type Permission string
+
+const (
+	PermissionProfileRead     Permission = "profile:read"
+	PermissionProfileWrite    Permission = "profile:write"
+	PermissionRoleManage      Permission = "role:manage"
+	PermissionUserRoleApprove Permission = "user-role:approve"
+	PermissionUserManage      Permission = "user:manage"
+)
+
+// Permissions — все известные права, роли можно выдать только их
+var Permissions = []Permission{
+	PermissionProfileRead,
+	PermissionProfileWrite,
+	PermissionRoleManage,
+	PermissionUserRoleApprove,
+	PermissionUserManage,
+}
+
+func (p Permission) IsKnown() bool {
+	for _, permission := range Permissions {
+		if permission == p {
+			return true
+		}
+	}
+
+	return false
+}
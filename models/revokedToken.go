@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var RevokedTokenCollection string = "revoked_tokens"
+
+// RevokedToken — запись в списке отзыва access токенов по jti.
+// Хранить ее имеет смысл только до ExpiresAt самого токена.
+type RevokedToken struct {
+	ID string
+
+	TokenID string
+	UserID  string
+
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type RevokedTokenDBSchema struct {
+	ID primitive.ObjectID `bson:"_id,omitempty"`
+
+	TokenID string             `bson:"tokenId"`
+	UserID  primitive.ObjectID `bson:"userId"`
+
+	ExpiresAt time.Time `bson:"expires_at"`
+	CreatedAt time.Time `bson:"created_at"`
+}
@@ -14,13 +14,28 @@ const (
 	RoleNameUser       RoleName = "user"
 	RoleNameSpecialist RoleName = "specialist"
 	RoleNameMinion     RoleName = "minion"
+	RoleNameAdmin      RoleName = "admin"
 )
 
+// DefaultRoles создаются при старте, если их еще нет в базе
+var DefaultRoles = []Role{
+	{Name: RoleNameUser, IsDefault: true, Permissions: []Permission{PermissionProfileRead, PermissionProfileWrite}},
+	{Name: RoleNameSpecialist, Permissions: []Permission{PermissionProfileRead, PermissionProfileWrite}},
+	{Name: RoleNameMinion, Permissions: []Permission{PermissionProfileRead, PermissionProfileWrite}},
+	{Name: RoleNameAdmin, Permissions: Permissions},
+}
+
+// IsSystem — на эти роли опирается код (регистрация, админка), их нельзя удалить или переименовать
+func (n RoleName) IsSystem() bool {
+	return n == RoleNameUser || n == RoleNameAdmin
+}
+
 type Role struct {
 	ID string
 
-	IsDefault bool
-	Name      RoleName
+	IsDefault   bool
+	Name        RoleName
+	Permissions []Permission
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -29,8 +44,9 @@ type Role struct {
 type RoleDBSchema struct {
 	ID primitive.ObjectID `bson:"_id,omitempty"`
 
-	IsDefault bool     `bson:"isDefault"`
-	Name      RoleName `bson:"name"`
+	IsDefault   bool         `bson:"isDefault"`
+	Name        RoleName     `bson:"name"`
+	Permissions []Permission `bson:"permissions"`
 
 	CreatedAt time.Time `bson:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at"`
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var SessionCollection string = "sessions"
+
+// Session — один выданный refresh token.
+// Все токены, полученные ротацией от одного входа, имеют общий FamilyID.
+type Session struct {
+	ID string
+
+	UserID        string
+	FamilyID      string
+	TokenHash     string
+	AccessTokenID string // jti последнего access токена этой сессии
+
+	Rotated bool
+	Revoked bool
+
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type SessionDBSchema struct {
+	ID primitive.ObjectID `bson:"_id,omitempty"`
+
+	UserID        primitive.ObjectID `bson:"userId"`
+	FamilyID      primitive.ObjectID `bson:"familyId"`
+	TokenHash     string             `bson:"tokenHash"`
+	AccessTokenID string             `bson:"accessTokenId"`
+
+	Rotated bool `bson:"rotated"`
+	Revoked bool `bson:"revoked"`
+
+	ExpiresAt time.Time `bson:"expires_at"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
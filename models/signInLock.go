@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// SignInLock — неудачные попытки входа по паролю.
+// После порога каждая следующая ошибка удваивает блокировку аккаунта.
+type SignInLock struct {
+	FailedAttempts int
+	LockedUntil    time.Time
+}
+
+type SignInLockDBSchema struct {
+	FailedAttempts int       `bson:"failedAttempts"`
+	LockedUntil    time.Time `bson:"locked_until"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// TwoFactor — настройки TOTP. Пока Enabled == false, Secret — это еще не подтвержденная регистрация.
+type TwoFactor struct {
+	Enabled bool
+	Secret  string
+	// LastUsedStep — шаг последнего принятого кода, чтобы один код нельзя было использовать дважды
+	LastUsedStep  int64
+	RecoveryCodes []string // хеши кодов восстановления
+
+	EnabledAt time.Time
+}
+
+type TwoFactorDBSchema struct {
+	Enabled       bool     `bson:"enabled"`
+	Secret        string   `bson:"secret"`
+	LastUsedStep  int64    `bson:"lastUsedStep"`
+	RecoveryCodes []string `bson:"recoveryCodes"`
+
+	EnabledAt time.Time `bson:"enabled_at"`
+}
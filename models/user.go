@@ -21,7 +21,7 @@ func (gender Gender) String() string {
 	if gender < Male || gender > NonBinary {
 		return "unknown"
 	}
-	return terms[gender]
+	return terms[gender-1]
 }
 
 type Address struct {
@@ -45,20 +45,33 @@ type User struct {
 	Password        string
 	PasswordConfirm string
 	Verified        bool
-	VerifyCode      string
+	VerifyCode      VerifyCode
+	EmailChange     EmailChange
+	TwoFactor       TwoFactor
+	SignInLock      SignInLock
+
+	// Suspended — аккаунт заблокирован администратором
+	Suspended   bool
+	SuspendedAt time.Time
 
 	UserRoleIDs []string
 	UserRoles   []*UserRoleWithRole
+	// RolesVersion растет при каждом изменении ролей юзера, попадает в claim rv токена
+	RolesVersion int
 
 	FinishedRegistration bool
 
-	IIN      int
+	// IIN — строка, у родившихся в 2000-х ИИН начинается с нуля
+	IIN      string
 	Name     string
 	Surname  string
 	Birthday time.Time
 	Gender   Gender
 	Address  Address
 
+	// Locale — язык писем и ошибок API, пустой — по Accept-Language запроса
+	Locale string
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -66,22 +79,65 @@ type User struct {
 type UserDBSchema struct {
 	ID primitive.ObjectID `bson:"_id,omitempty"`
 
-	Email           string `bson:"email"`
-	Password        string `bson:"password"`
-	PasswordConfirm string `bson:"passwordConfirm"`
-	Verified        bool   `bson:"verified"`
-	VerifyCode      string `bson:"verifyCode"`
+	Email           string              `bson:"email"`
+	Password        string              `bson:"password"`
+	PasswordConfirm string              `bson:"passwordConfirm"`
+	Verified        bool                `bson:"verified"`
+	VerifyCode      VerifyCodeDBSchema  `bson:"verification"`
+	EmailChange     EmailChangeDBSchema `bson:"emailChange"`
+	TwoFactor       TwoFactorDBSchema   `bson:"twoFactor"`
+	SignInLock      SignInLockDBSchema  `bson:"signInLock"`
+
+	Suspended   bool      `bson:"suspended"`
+	SuspendedAt time.Time `bson:"suspended_at"`
 
 	UserRoleIDs          []primitive.ObjectID `bson:"userRoleIds"`
+	RolesVersion         int                  `bson:"rolesVersion"`
 	FinishedRegistration bool                 `bson:"finishedRegistration"`
 
-	IIN      int             `bson:"IIN"`
+	IIN      string          `bson:"IIN"`
 	Name     string          `bson:"name"`
 	Surname  string          `bson:"surname"`
 	Birthday time.Time       `bson:"birthday"`
 	Gender   Gender          `bson:"gender"`
 	Address  AddressDBSchema `bson:"address"`
 
+	Locale string `bson:"locale"`
+
 	CreatedAt time.Time `bson:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at"`
-}
\ No newline at end of file
+}
+
+// HasAnyRole — есть ли у юзера хотя бы одна из ролей в статусе approved
+func (u *User) HasAnyRole(names ...RoleName) bool {
+	for _, userRole := range u.UserRoles {
+		if userRole.Status != UserRoleStatusApproved {
+			continue
+		}
+
+		for _, name := range names {
+			if userRole.Name == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// HasPermission — дает ли право хотя бы одна одобренная роль юзера
+func (u *User) HasPermission(permission Permission) bool {
+	for _, userRole := range u.UserRoles {
+		if userRole.Status != UserRoleStatusApproved {
+			continue
+		}
+
+		for _, p := range userRole.Permissions {
+			if p == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
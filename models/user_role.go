@@ -23,6 +23,11 @@ type UserRole struct {
 	RoleID string
 	Status UserRoleStatus
 
+	// Решение администратора по заявке
+	DecidedBy string
+	DecidedAt time.Time
+	Reason    string
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -34,14 +39,19 @@ type UserRoleDBSchema struct {
 	RoleID primitive.ObjectID `bson:"roleId"`
 	Status UserRoleStatus     `bson:"status"`
 
+	DecidedBy primitive.ObjectID `bson:"decidedBy,omitempty"`
+	DecidedAt time.Time          `bson:"decided_at,omitempty"`
+	Reason    string             `bson:"reason,omitempty"`
+
 	CreatedAt time.Time `bson:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at"`
 }
 
 type UserRoleWithRole struct {
-	IsDefault bool
-	Name      RoleName
-	Status    UserRoleStatus
+	IsDefault   bool
+	Name        RoleName
+	Status      UserRoleStatus
+	Permissions []Permission
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// VerifyCode — одноразовый код из письма. Сам код не храним, только его хеш.
+type VerifyCode struct {
+	Hash      string
+	ExpiresAt time.Time
+	SentAt    time.Time
+
+	Attempts    int
+	LockedUntil time.Time
+}
+
+type VerifyCodeDBSchema struct {
+	Hash      string    `bson:"hash"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	SentAt    time.Time `bson:"sent_at"`
+
+	Attempts    int       `bson:"attempts"`
+	LockedUntil time.Time `bson:"locked_until"`
+}
+
+// EmailChange — запрошенная смена email, ждет подтверждения кодом с нового адреса
+type EmailChange struct {
+	Email string
+	Code  VerifyCode
+}
+
+type EmailChangeDBSchema struct {
+	Email string             `bson:"email"`
+	Code  VerifyCodeDBSchema `bson:"code"`
+}
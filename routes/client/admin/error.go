@@ -0,0 +1,21 @@
+package admin
+
+import (
+	"health/shared/errs"
+	"health/shared/types"
+)
+
+var (
+	ErrUserNotFound = types.Error{
+		Code:    errs.AdminUserNotFound,
+		Message: "User not found",
+		Field:   "id",
+		Tag:     "admin",
+	}
+	ErrCantManageSelf = types.Error{
+		Code:    errs.AdminCantManageSelf,
+		Message: "You can`t suspend or delete your own account",
+		Field:   "id",
+		Tag:     "admin",
+	}
+)
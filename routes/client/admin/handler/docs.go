@@ -0,0 +1,52 @@
+package adminHandler
+
+import (
+	"health/models"
+	"health/routes/client/admin"
+	"health/services/openapi"
+	"health/shared/errs"
+	"net/http"
+)
+
+const docsTag = "admin"
+
+// Endpoints — описание роутов из RegisterHTTPEndpoints для OpenAPI спецификации
+func (m *Module) Endpoints() []openapi.Endpoint {
+	endpoints := []openapi.Endpoint{
+		{
+			Method: http.MethodGet, Path: "/api/admin/v1/users", Summary: "Users with filters and paging",
+			Query: admin.ListUsersInput{},
+			Data:  UsersResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/admin/v1/users/:id", Summary: "User with all role records",
+			Data:   UserResponse{},
+			Errors: []errs.Code{errs.AdminUserNotFound},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/admin/v1/users/:id/suspend", Summary: "Suspend a user and revoke their sessions",
+			Errors: []errs.Code{errs.AdminUserNotFound, errs.AdminCantManageSelf, errs.AuthUserUpdateFailed},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/admin/v1/users/:id/unsuspend", Summary: "Lift a suspension",
+			Errors: []errs.Code{errs.AdminUserNotFound, errs.AuthUserUpdateFailed},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/admin/v1/users/:id/verify", Summary: "Mark the user as verified",
+			Errors: []errs.Code{errs.AdminUserNotFound, errs.AuthUserUpdateFailed},
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/admin/v1/users/:id", Summary: "Delete a user with roles and sessions",
+			Errors: []errs.Code{errs.AdminUserNotFound, errs.AdminCantManageSelf},
+		},
+	}
+
+	// * Вся группа /api/admin/v1 под токеном и правом user:manage
+	for i := range endpoints {
+		endpoints[i].Tag = docsTag
+		endpoints[i].Auth = true
+		endpoints[i].Permission = string(models.PermissionUserManage)
+	}
+
+	return endpoints
+}
@@ -0,0 +1,97 @@
+package adminHandler
+
+import (
+	"context"
+	"health/models"
+	"health/routes/client/admin"
+	"health/routes/client/auth"
+	"health/services/validation"
+	"health/shared/types"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	useCase admin.UseCase
+}
+
+func NewHandler(useCase admin.UseCase) *Handler {
+	return &Handler{
+		useCase: useCase,
+	}
+}
+
+func (h *Handler) ListUsers(c *gin.Context) {
+	inp := new(admin.ListUsersInput)
+
+	if !validation.BindQuery(c, inp, "admin") {
+		return
+	}
+
+	list, err := h.useCase.ListUsers(c.Request.Context(), inp)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.GoodResponse{
+		Code: http.StatusOK,
+		Data: UsersResponse{
+			Items: list.Items,
+			Total: list.Total,
+			Page:  list.Page,
+			Limit: list.Limit,
+		},
+	})
+}
+
+func (h *Handler) GetUser(c *gin.Context) {
+	details, err := h.useCase.GetUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.GoodResponse{
+		Code: http.StatusOK,
+		Data: UserResponse{
+			User:      details.User,
+			UserRoles: details.UserRoles,
+		},
+	})
+}
+
+func (h *Handler) SuspendUser(c *gin.Context) {
+	h.manage(c, h.useCase.SuspendUser)
+}
+
+func (h *Handler) UnsuspendUser(c *gin.Context) {
+	h.manage(c, h.useCase.UnsuspendUser)
+}
+
+func (h *Handler) VerifyUser(c *gin.Context) {
+	h.manage(c, h.useCase.VerifyUser)
+}
+
+func (h *Handler) DeleteUser(c *gin.Context) {
+	h.manage(c, h.useCase.DeleteUser)
+}
+
+func (h *Handler) manage(c *gin.Context, action func(ctx context.Context, inp *admin.ManageUserInput) *types.Error) {
+	inp := &admin.ManageUserInput{
+		ID: c.Param("id"),
+	}
+
+	// c токена вытаскиваем
+	if user, exist := c.Get(auth.CtxUserKey); exist {
+		inp.AdminID = user.(*models.User).ID
+	}
+
+	if err := action(c.Request.Context(), inp); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
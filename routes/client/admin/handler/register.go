@@ -0,0 +1,39 @@
+package adminHandler
+
+import (
+	"health/models"
+	"health/routes/client/admin"
+	roleHandler "health/routes/client/role/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Module struct {
+	handler        *Handler
+	authMiddleware gin.HandlerFunc
+}
+
+func NewModule(uc admin.UseCase, authMiddleware gin.HandlerFunc) *Module {
+	return &Module{
+		handler:        NewHandler(uc),
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (m *Module) RegisterHTTPEndpoints(router *gin.RouterGroup) {
+	h := m.handler
+
+	// Create the middleware instance
+	canManageUsers := roleHandler.RequirePermission(models.PermissionUserManage)
+
+	// Create the endpoints
+	endpoints := router.Group("/api/admin/v1", m.authMiddleware, canManageUsers)
+	{
+		endpoints.GET("/users", h.ListUsers)
+		endpoints.GET("/users/:id", h.GetUser)
+		endpoints.POST("/users/:id/suspend", h.SuspendUser)
+		endpoints.POST("/users/:id/unsuspend", h.UnsuspendUser)
+		endpoints.POST("/users/:id/verify", h.VerifyUser)
+		endpoints.DELETE("/users/:id", h.DeleteUser)
+	}
+}
@@ -0,0 +1,19 @@
+package adminHandler
+
+import (
+	"health/models"
+	"health/routes/client/admin"
+)
+
+// UsersResponse — страница юзеров
+type UsersResponse struct {
+	Items []*models.User `json:"items"`
+	Total int64          `json:"total"`
+	Page  int64          `json:"page"`
+	Limit int64          `json:"limit"`
+}
+
+type UserResponse struct {
+	User      *models.User          `json:"user"`
+	UserRoles []*admin.UserRoleView `json:"userRoles"`
+}
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"context"
+	"health/models"
+	"health/shared/types"
+	"time"
+)
+
+// UserRoleView — запись user.roles с названием роли и решением по ней
+type UserRoleView struct {
+	ID       string
+	RoleID   string
+	RoleName models.RoleName
+	Status   models.UserRoleStatus
+
+	DecidedBy string
+	DecidedAt time.Time
+	Reason    string
+
+	CreatedAt time.Time
+}
+
+type UserDetails struct {
+	User      *models.User
+	UserRoles []*UserRoleView
+}
+
+type UserList struct {
+	Items []*models.User
+	Total int64
+	Page  int64
+	Limit int64
+}
+
+type UseCase interface {
+	ListUsers(ctx context.Context, inp *ListUsersInput) (*UserList, *types.Error)
+	GetUser(ctx context.Context, id string) (*UserDetails, *types.Error)
+
+	SuspendUser(ctx context.Context, inp *ManageUserInput) *types.Error
+	UnsuspendUser(ctx context.Context, inp *ManageUserInput) *types.Error
+	VerifyUser(ctx context.Context, inp *ManageUserInput) *types.Error
+	DeleteUser(ctx context.Context, inp *ManageUserInput) *types.Error
+}
@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"context"
+	"health/models"
+	"health/services/transaction"
+	"time"
+
+	"health/routes/client/admin"
+	"health/routes/client/auth"
+	"health/routes/client/role"
+	"health/routes/client/userRole"
+	"health/shared/errs"
+	"health/shared/types"
+	"health/shared/utils"
+)
+
+const defaultUsersLimit = 20
+
+// * поле запроса -> поле в базе
+var sortFields = map[string]string{
+	"createdAt": "created_at",
+	"updatedAt": "updated_at",
+	"email":     "email",
+}
+
+type UseCase struct {
+	userRepo     auth.Repository
+	roleRepo     role.Repository
+	userRoleRepo userRole.Repository
+	txManager    transaction.Manager
+
+	// * Сессии и токены юзера закрывает auth, у него же кеш юзеров
+	auth auth.UseCase
+}
+
+func NewUseCase(
+	userRepo auth.Repository,
+	roleRepo role.Repository,
+	userRoleRepo userRole.Repository,
+	txManager transaction.Manager,
+	authUseCase auth.UseCase) *UseCase {
+	return &UseCase{
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		userRoleRepo: userRoleRepo,
+		txManager:    txManager,
+		auth:         authUseCase,
+	}
+}
+
+func (a *UseCase) ListUsers(ctx context.Context, inp *admin.ListUsersInput) (*admin.UserList, *types.Error) {
+	page, limit := inp.Page, inp.Limit
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = defaultUsersLimit
+	}
+
+	filter := &auth.UserFilter{
+		Email:                inp.Email,
+		Verified:             parseBool(inp.Verified),
+		FinishedRegistration: parseBool(inp.FinishedRegistration),
+		Suspended:            parseBool(inp.Suspended),
+		SortBy:               sortFields[inp.SortBy],
+		SortDesc:             inp.Order == "desc",
+		Skip:                 (page - 1) * limit,
+		Limit:                limit,
+	}
+
+	// * Фильтр по ролям: сначала находим юзеров с подходящими записями user.roles
+	if inp.RoleStatus != "" || inp.RoleID != "" {
+		userRoles, _, err := a.userRoleRepo.GetUserRoles(ctx, &userRole.ListFilter{
+			Status: models.UserRoleStatus(inp.RoleStatus),
+			RoleID: inp.RoleID,
+		})
+		if err != nil {
+			return nil, &types.Error{
+				Code:  errs.Internal,
+				Field: "list-users",
+				Tag:   "admin",
+				Cause: err,
+			}
+		}
+
+		filter.IDs = make([]string, 0, len(userRoles))
+		for _, userRoleEntity := range userRoles {
+			filter.IDs = append(filter.IDs, userRoleEntity.UserID)
+		}
+	}
+
+	users, total, err := a.userRepo.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "list-users",
+			Tag:   "admin",
+			Cause: err,
+		}
+	}
+
+	for _, user := range users {
+		clearUser(user)
+	}
+
+	return &admin.UserList{
+		Items: users,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}, nil
+}
+
+func (a *UseCase) GetUser(ctx context.Context, id string) (*admin.UserDetails, *types.Error) {
+	user, err := a.userRepo.GetUserById(ctx, id)
+	if err != nil {
+		return nil, &admin.ErrUserNotFound
+	}
+
+	userRoles, err := a.userRoleRepo.GetUserRoleByIDs(ctx, user.UserRoleIDs)
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "get-user",
+			Tag:   "admin",
+			Cause: err,
+		}
+	}
+
+	roles, err := a.roleRepo.GetRoles(ctx)
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "get-user",
+			Tag:   "admin",
+			Cause: err,
+		}
+	}
+
+	roleNames := make(map[string]models.RoleName, len(roles))
+	for _, roleEntity := range roles {
+		roleNames[roleEntity.ID] = roleEntity.Name
+	}
+
+	views := make([]*admin.UserRoleView, 0, len(userRoles))
+	for _, userRoleEntity := range userRoles {
+		views = append(views, &admin.UserRoleView{
+			ID:        userRoleEntity.ID,
+			RoleID:    userRoleEntity.RoleID,
+			RoleName:  roleNames[userRoleEntity.RoleID],
+			Status:    userRoleEntity.Status,
+			DecidedBy: userRoleEntity.DecidedBy,
+			DecidedAt: userRoleEntity.DecidedAt,
+			Reason:    userRoleEntity.Reason,
+			CreatedAt: userRoleEntity.CreatedAt,
+		})
+	}
+
+	return &admin.UserDetails{
+		User:      clearUser(user),
+		UserRoles: views,
+	}, nil
+}
+
+// SuspendUser блокирует аккаунт, закрывает его сессии и сразу отзывает выданные access токены
+func (a *UseCase) SuspendUser(ctx context.Context, inp *admin.ManageUserInput) *types.Error {
+	if inp.ID == inp.AdminID {
+		return &admin.ErrCantManageSelf
+	}
+
+	user, err := a.userRepo.GetUserById(ctx, inp.ID)
+	if err != nil {
+		return &admin.ErrUserNotFound
+	}
+
+	if user.Suspended {
+		return nil
+	}
+
+	user.Suspended = true
+	user.SuspendedAt = time.Now()
+	if err := a.userRepo.UpdateUser(ctx, user); err != nil {
+		return &auth.ErrCantUpdateUser
+	}
+
+	if err := a.auth.RevokeUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *UseCase) UnsuspendUser(ctx context.Context, inp *admin.ManageUserInput) *types.Error {
+	user, err := a.userRepo.GetUserById(ctx, inp.ID)
+	if err != nil {
+		return &admin.ErrUserNotFound
+	}
+
+	if !user.Suspended {
+		return nil
+	}
+
+	user.Suspended = false
+	user.SuspendedAt = time.Time{}
+	if err := a.userRepo.UpdateUser(ctx, user); err != nil {
+		return &auth.ErrCantUpdateUser
+	}
+	// * Иначе до user_cache_ttl middleware отдает закешированного заблокированного юзера
+	a.auth.ForgetUser(user.ID)
+
+	return nil
+}
+
+func (a *UseCase) VerifyUser(ctx context.Context, inp *admin.ManageUserInput) *types.Error {
+	user, err := a.userRepo.GetUserById(ctx, inp.ID)
+	if err != nil {
+		return &admin.ErrUserNotFound
+	}
+
+	if user.Verified {
+		return nil
+	}
+
+	user.Verified = true
+	user.VerifyCode = models.VerifyCode{}
+	if err := a.userRepo.UpdateUser(ctx, user); err != nil {
+		return &auth.ErrCantUpdateUser
+	}
+	a.auth.ForgetUser(user.ID)
+
+	return nil
+}
+
+// DeleteUser удаляет юзера вместе с его записями user.roles и закрывает его сессии
+func (a *UseCase) DeleteUser(ctx context.Context, inp *admin.ManageUserInput) *types.Error {
+	if inp.ID == inp.AdminID {
+		return &admin.ErrCantManageSelf
+	}
+
+	user, err := a.userRepo.GetUserById(ctx, inp.ID)
+	if err != nil {
+		return &admin.ErrUserNotFound
+	}
+
+	if err := a.auth.RevokeUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	// * Записи user.roles и сам юзер удаляются вместе: при сбое не остается полуудаленного юзера
+	err = a.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := a.userRoleRepo.DeleteUserRolesByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+
+		return a.userRepo.DeleteUser(ctx, user.ID)
+	})
+	if err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "delete-user",
+			Tag:   "admin",
+			Cause: err,
+		}
+	}
+	// * Пока шло удаление, параллельный запрос мог снова положить юзера в кеш
+	a.auth.ForgetUser(user.ID)
+
+	return nil
+}
+
+// clearUser убирает из юзера секреты перед отдачей наружу
+func clearUser(user *models.User) *models.User {
+	utils.RemoveKeyFromStruct(user, "Password")
+	utils.RemoveKeyFromStruct(user, "PasswordConfirm")
+	utils.RemoveKeyFromStruct(user, "VerifyCode")
+	utils.RemoveKeyFromStruct(user, "EmailChange")
+	utils.RemoveKeyFromStruct(user, "TwoFactor")
+
+	return user
+}
+
+func parseBool(value string) *bool {
+	if value == "" {
+		return nil
+	}
+
+	res := value == "true"
+	return &res
+}
@@ -0,0 +1,22 @@
+package admin
+
+type ListUsersInput struct {
+	Page  int64 `form:"page"  validate:"omitempty,min=1"`
+	Limit int64 `form:"limit" validate:"omitempty,min=1,max=100"`
+
+	Email                string `form:"email"`
+	Verified             string `form:"verified"             validate:"omitempty,oneof=true false"`
+	FinishedRegistration string `form:"finishedRegistration" validate:"omitempty,oneof=true false"`
+	Suspended            string `form:"suspended"            validate:"omitempty,oneof=true false"`
+	// RoleStatus и RoleID — юзеры, у которых есть запись user.roles с таким статусом и/или ролью
+	RoleStatus string `form:"roleStatus" validate:"omitempty,oneof=pending approved canceled"`
+	RoleID     string `form:"roleId"`
+
+	SortBy string `form:"sortBy" validate:"omitempty,oneof=createdAt updatedAt email"`
+	Order  string `form:"order"  validate:"omitempty,oneof=asc desc"`
+}
+
+type ManageUserInput struct {
+	ID      string // id юзера из пути
+	AdminID string
+}
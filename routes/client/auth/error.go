@@ -1,61 +1,199 @@
 package auth
 
 import (
+	"health/shared/errs"
 	"health/shared/types"
 )
 
 var (
 	ErrEmailOrPassword = types.Error{
+		Code:    errs.AuthInvalidCredentials,
 		Message: "Email or Password is wrong",
 		Field:   "email/password",
 		Tag:     "auth",
 	}
-	ErrPasswordNotEqual = types.Error{
-		Message: "Password not equal",
-		Field:   "password",
-		Tag:     "auth",
-	}
 	ErrUserIsUnauthorized = types.Error{
+		Code:    errs.AuthUnauthorized,
 		Message: "User is unauthorized",
 		Field:   "token",
 		Tag:     "auth",
 	}
 	ErrUserNotFound = types.Error{
+		Code:    errs.AuthUserNotFound,
 		Message: "User not found",
-		Field:   "sign-in",
+		Field:   "email",
 		Tag:     "auth",
 	}
 	ErrUserIsExist = types.Error{
+		Code:    errs.AuthUserExists,
 		Message: "User is exist",
-		Field:   "sign-up",
+		Field:   "email",
+		Tag:     "auth",
+	}
+	ErrIINIsExist = types.Error{
+		Code:    errs.AuthIINExists,
+		Message: "User with this IIN already exists",
+		Field:   "IIN",
 		Tag:     "auth",
 	}
 	ErrVerifyCodeNotMatch = types.Error{
+		Code:    errs.AuthVerifyCodeInvalid,
 		Message: "Verify code is invalid",
-		Field:   "sign-up",
+		Field:   "verifyCode",
+		Tag:     "auth",
+	}
+	ErrVerifyCodeExpired = types.Error{
+		Code:    errs.AuthVerifyCodeExpired,
+		Message: "Verify code is expired, request a new one",
+		Field:   "verifyCode",
+		Tag:     "auth",
+	}
+	ErrVerifyCodeTooManyAttempts = types.Error{
+		Code:    errs.AuthVerifyCodeTooManyTries,
+		Message: "Too many wrong verify codes, try again later",
+		Field:   "verifyCode",
+		Tag:     "auth",
+	}
+	ErrVerifyCodeLocked = types.Error{
+		Code:    errs.AuthVerifyCodeLocked,
+		Message: "Verify code is locked after too many wrong attempts, try again later",
+		Field:   "verifyCode",
+		Tag:     "auth",
+	}
+	ErrVerifyCodeResendTooSoon = types.Error{
+		Code:    errs.AuthVerifyCodeResendTooSoon,
+		Message: "Verify code was sent recently, wait before requesting a new one",
+		Field:   "verifyCode",
 		Tag:     "auth",
 	}
 	ErrInvalidAccessToken = types.Error{
+		Code:    errs.AuthAccessTokenInvalid,
 		Message: "Invalid access token",
-		Field:   "parse token",
+		Field:   "token",
+		Tag:     "auth",
+	}
+	ErrAccessTokenExpired = types.Error{
+		Code:    errs.AuthAccessTokenExpired,
+		Message: "Access token is expired",
+		Field:   "token",
+		Tag:     "auth",
+	}
+	ErrAccessTokenRevoked = types.Error{
+		Code:    errs.AuthAccessTokenRevoked,
+		Message: "Access token is revoked",
+		Field:   "token",
+		Tag:     "auth",
+	}
+	ErrInvalidRefreshToken = types.Error{
+		Code:    errs.AuthRefreshTokenInvalid,
+		Message: "Invalid refresh token",
+		Field:   "refreshToken",
+		Tag:     "auth",
+	}
+	ErrRefreshTokenExpired = types.Error{
+		Code:    errs.AuthRefreshTokenExpired,
+		Message: "Refresh token is expired",
+		Field:   "refreshToken",
+		Tag:     "auth",
+	}
+	ErrRefreshTokenReused = types.Error{
+		Code:    errs.AuthRefreshTokenReused,
+		Message: "Refresh token has already been used, session is revoked",
+		Field:   "refreshToken",
+		Tag:     "auth",
+	}
+	ErrInvalidResetToken = types.Error{
+		Code:    errs.AuthResetTokenInvalid,
+		Message: "Password reset link is invalid or has expired",
+		Field:   "token",
+		Tag:     "auth",
+	}
+	ErrEmailIsSame = types.Error{
+		Code:    errs.AuthEmailIsSame,
+		Message: "New email is the same as the current one",
+		Field:   "email",
+		Tag:     "auth",
+	}
+	ErrEmailChangeNotRequested = types.Error{
+		Code:    errs.AuthEmailChangeNotRequested,
+		Message: "Email change was not requested",
+		Field:   "verifyCode",
+		Tag:     "auth",
+	}
+	ErrUserSuspended = types.Error{
+		Code:    errs.AuthUserSuspended,
+		Message: "Account is suspended",
+		Field:   "email",
+		Tag:     "auth",
+	}
+	ErrAccountLocked = types.Error{
+		Code:    errs.AuthAccountLocked,
+		Message: "Too many failed sign in attempts, account is temporarily locked",
+		Field:   "email/password",
+		Tag:     "auth",
+	}
+	ErrTooManyRequests = types.Error{
+		Code:    errs.AuthTooManyRequests,
+		Message: "Too many requests, try again later",
+		Field:   "rate-limit",
+		Tag:     "auth",
+	}
+	ErrPasswordSignInDisabled = types.Error{
+		Code:    errs.AuthPasswordSignInDisabled,
+		Message: "Sign in with password is disabled, use a code sent to your email",
+		Field:   "password",
+		Tag:     "auth",
+	}
+	ErrTwoFactorAlreadyEnabled = types.Error{
+		Code:    errs.AuthTwoFactorEnabled,
+		Message: "Two-factor authentication is already enabled",
+		Field:   "2fa",
+		Tag:     "auth",
+	}
+	ErrTwoFactorNotEnabled = types.Error{
+		Code:    errs.AuthTwoFactorNotEnabled,
+		Message: "Two-factor authentication is not enabled",
+		Field:   "2fa",
+		Tag:     "auth",
+	}
+	ErrTwoFactorNotEnrolled = types.Error{
+		Code:    errs.AuthTwoFactorNotEnrolled,
+		Message: "Two-factor enrollment was not started",
+		Field:   "2fa",
+		Tag:     "auth",
+	}
+	ErrInvalidTwoFactorCode = types.Error{
+		Code:    errs.AuthTwoFactorCodeInvalid,
+		Message: "Two-factor code is invalid",
+		Field:   "code",
+		Tag:     "auth",
+	}
+	ErrInvalidMFAToken = types.Error{
+		Code:    errs.AuthMFATokenInvalid,
+		Message: "MFA token is invalid or has expired, sign in again",
+		Field:   "mfaToken",
 		Tag:     "auth",
 	}
 	ErrRoleIsExist = types.Error{
+		Code:    errs.AuthRoleExists,
 		Message: "This role already exists for the user",
-		Field:   "role-ids",
+		Field:   "roleIds",
 		Tag:     "auth",
 	}
 	ErrCantFindUserRole = types.Error{
+		Code:    errs.AuthUserRoleNotFound,
 		Message: "Can`t find role",
 		Field:   "role_id",
 		Tag:     "user-role",
 	}
 	ErrCantDeleteUserRole = types.Error{
+		Code:    errs.AuthUserRoleDeleteFailed,
 		Message: "Can`t delete role",
 		Field:   "role_id",
 		Tag:     "user-role",
 	}
 	ErrCantUpdateUser = types.Error{
+		Code:    errs.AuthUserUpdateFailed,
 		Message: "Can`t update user",
 		Field:   "user_id",
 		Tag:     "user",
@@ -0,0 +1,137 @@
+package authHandler
+
+import (
+	"health/models"
+	"health/routes/client/auth"
+	"health/services/jwk"
+	"health/services/openapi"
+	"health/shared/errs"
+	"net/http"
+)
+
+const docsTag = "auth"
+
+// Endpoints — описание роутов из RegisterHTTPEndpoints для OpenAPI спецификации
+func (m *Module) Endpoints() []openapi.Endpoint {
+	// * Коды проверки пароля при входе и отправке кода
+	loginErrors := []errs.Code{errs.AuthUserNotFound, errs.AuthInvalidCredentials, errs.AuthAccountLocked, errs.AuthUserUpdateFailed}
+	// * Коды проверки кода из письма
+	codeErrors := []errs.Code{errs.AuthVerifyCodeInvalid, errs.AuthVerifyCodeExpired, errs.AuthVerifyCodeTooManyTries, errs.AuthVerifyCodeLocked}
+
+	endpoints := []openapi.Endpoint{
+		{
+			Method: http.MethodPost, Path: "/auth/v1/sign-up", Summary: "Sign up with email and password",
+			Body:   auth.SignUpInput{},
+			Errors: []errs.Code{errs.AuthUserExists},
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/send-verify-code", Summary: "Email a verification code",
+			Body:   auth.SendVerifyCodeInput{},
+			Errors: append(loginErrors, errs.AuthVerifyCodeLocked, errs.AuthVerifyCodeResendTooSoon),
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/check-verify-code", Summary: "Verify the emailed code and sign in",
+			Body:   auth.CheckVerifyCodeInput{},
+			Data:   SignInResponse{},
+			Errors: append(append(loginErrors, codeErrors...), errs.AuthUserSuspended),
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/sign-in", Summary: "Sign in with email and password, returns tokens or an MFA token",
+			Body:   auth.SignInInput{},
+			Data:   SignInResponse{},
+			Errors: append(loginErrors, errs.AuthUnauthorized, errs.AuthPasswordSignInDisabled, errs.AuthUserSuspended),
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/refresh", Summary: "Rotate the refresh token",
+			Body:   auth.RefreshInput{},
+			Data:   TokensResponse{},
+			Errors: []errs.Code{errs.AuthRefreshTokenInvalid, errs.AuthRefreshTokenExpired, errs.AuthRefreshTokenReused, errs.AuthUserNotFound, errs.AuthUserSuspended},
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/forgot-password", Summary: "Email a password reset link, always succeeds",
+			Body: auth.ForgotPasswordInput{},
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/reset-password", Summary: "Set a new password with a reset link token",
+			Body:   auth.ResetPasswordInput{},
+			Errors: []errs.Code{errs.AuthResetTokenInvalid, errs.AuthUserUpdateFailed},
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/2fa/verify", Summary: "Second sign in step with a TOTP or recovery code",
+			Body:   auth.VerifyTwoFactorInput{},
+			Data:   TokensResponse{},
+			Errors: []errs.Code{errs.AuthMFATokenInvalid, errs.AuthTwoFactorCodeInvalid, errs.AuthUserUpdateFailed, errs.AuthUserSuspended},
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/update-profile", Summary: "Finish registration or update personal data",
+			Auth: true, Permission: string(models.PermissionProfileWrite),
+			Body:   auth.UpdateProfileInput{},
+			Data:   UpdateProfileResponse{},
+			Errors: []errs.Code{errs.AuthUserNotFound, errs.AuthIINExists, errs.AuthUserRoleDeleteFailed, errs.AuthUserUpdateFailed},
+		},
+		{
+			Method: http.MethodGet, Path: "/auth/v1/get-profile", Summary: "Current user with roles",
+			Auth: true, Permission: string(models.PermissionProfileRead),
+			Data:   ProfileResponse{},
+			Errors: []errs.Code{errs.AuthUserNotFound},
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/sign-out", Summary: "Revoke the current session",
+			Auth: true,
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/sign-out-all", Summary: "Revoke all sessions of the user",
+			Auth: true,
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/change-password", Summary: "Change password, other sessions are revoked",
+			Auth:   true,
+			Body:   auth.ChangePasswordInput{},
+			Data:   TokensResponse{},
+			Errors: []errs.Code{errs.AuthUserNotFound, errs.AuthInvalidCredentials, errs.AuthUserUpdateFailed},
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/change-email", Summary: "Email a confirmation code to the new address",
+			Auth:   true,
+			Body:   auth.ChangeEmailInput{},
+			Errors: []errs.Code{errs.AuthUserNotFound, errs.AuthInvalidCredentials, errs.AuthEmailIsSame, errs.AuthUserExists, errs.AuthUserUpdateFailed, errs.AuthVerifyCodeLocked, errs.AuthVerifyCodeResendTooSoon},
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/change-email/confirm", Summary: "Confirm the new email with the code",
+			Auth:   true,
+			Body:   auth.ConfirmChangeEmailInput{},
+			Errors: append([]errs.Code{errs.AuthUserNotFound, errs.AuthEmailChangeNotRequested, errs.AuthUserExists, errs.AuthUserUpdateFailed}, codeErrors...),
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/2fa/enroll", Summary: "Start TOTP enrollment",
+			Auth:   true,
+			Body:   auth.EnrollTwoFactorInput{},
+			Data:   TwoFactorEnrollmentResponse{},
+			Errors: []errs.Code{errs.AuthUserNotFound, errs.AuthTwoFactorEnabled, errs.AuthInvalidCredentials, errs.AuthUserUpdateFailed},
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/2fa/confirm", Summary: "Enable 2FA with the first code, returns recovery codes",
+			Auth:   true,
+			Body:   auth.ConfirmTwoFactorInput{},
+			Data:   RecoveryCodesResponse{},
+			Errors: []errs.Code{errs.AuthUserNotFound, errs.AuthTwoFactorEnabled, errs.AuthTwoFactorNotEnrolled, errs.AuthTwoFactorCodeInvalid, errs.AuthUserUpdateFailed},
+		},
+		{
+			Method: http.MethodPost, Path: "/auth/v1/2fa/disable", Summary: "Disable 2FA",
+			Auth:   true,
+			Body:   auth.DisableTwoFactorInput{},
+			Errors: []errs.Code{errs.AuthUserNotFound, errs.AuthTwoFactorNotEnabled, errs.AuthInvalidCredentials, errs.AuthTwoFactorCodeInvalid, errs.AuthUserUpdateFailed},
+		},
+	}
+
+	// * На всей группе /auth/v1 висит rate limit
+	for i := range endpoints {
+		endpoints[i].Tag = docsTag
+		endpoints[i].Errors = append(endpoints[i].Errors, errs.AuthTooManyRequests)
+	}
+
+	return append(endpoints, openapi.Endpoint{
+		Method: http.MethodGet, Path: "/.well-known/jwks.json", Tag: docsTag, Summary: "Public keys for access token verification",
+		Raw: jwk.JSONWebKeySet{},
+	})
+}
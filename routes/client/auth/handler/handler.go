@@ -3,6 +3,7 @@ package authHandler
 import (
 	"health/models"
 	"health/routes/client/auth"
+	"health/services/validation"
 	"health/shared/types"
 	"net/http"
 
@@ -22,110 +23,121 @@ func NewHandler(useCase auth.UseCase) *Handler {
 func (h *Handler) SignUp(c *gin.Context) {
 	inp := new(auth.SignUpInput)
 
-	if err := c.BindJSON(inp); err != nil {
-		c.JSON(http.StatusBadRequest, types.BadResponse{
-			Code: http.StatusBadRequest,
-			Error: &types.Error{
-				Message: err.Error(),
-				Field:   "input data",
-				Tag:     "auth",
-			},
-		})
+	if !validation.BindJSON(c, inp, "auth") {
 		return
 	}
 
-	if err := auth.ValidateSignUpInput(inp); err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
+	if err := h.useCase.SignUp(c.Request.Context(), inp); err != nil {
+		c.Error(err)
+		return
+	}
 
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) SendVerifyCode(c *gin.Context) {
+	inp := new(auth.SendVerifyCodeInput)
+
+	if !validation.BindJSON(c, inp, "auth") {
 		return
 	}
 
-	if err := h.useCase.SignUp(c.Request.Context(), inp); err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
+	if err := h.useCase.SendVerifyCode(c.Request.Context(), inp); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
 
-func (h *Handler) SendVerifyCode(c *gin.Context) {
-	inp := new(auth.SendVerifyCodeInput)
+func (h *Handler) CheckVerifyCode(c *gin.Context) {
+	inp := new(auth.CheckVerifyCodeInput)
 
-	if err := c.BindJSON(inp); err != nil {
-		c.JSON(http.StatusBadRequest, types.BadResponse{
-			Code: http.StatusBadRequest,
-			Error: &types.Error{
-				Message: err.Error(),
-				Field:   "input data",
-				Tag:     "auth",
-			},
-		})
+	if !validation.BindJSON(c, inp, "auth") {
+		return
+	}
+
+	res, err := h.useCase.CheckVerifyCode(c.Request.Context(), inp)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	if err := auth.ValidateSendVerifyCodeInput(inp); err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
+	renderSignInResult(c, res)
+}
+
+// renderSignInResult отдает пару токенов или MFA токен, если вход нужно подтвердить кодом 2FA
+func renderSignInResult(c *gin.Context, res *auth.SignInResult) {
+	switch {
+	case res.MFAToken != "":
+		c.JSON(http.StatusOK, types.GoodResponse{
+			Code: http.StatusOK,
+			Data: SignInResponse{
+				MFARequired: true,
+				MFAToken:    res.MFAToken,
+			},
 		})
+	case res.Tokens != nil:
+		c.JSON(http.StatusOK, types.GoodResponse{
+			Code: http.StatusOK,
+			Data: SignInResponse{
+				Token:        res.Tokens.AccessToken,
+				RefreshToken: res.Tokens.RefreshToken,
+			},
+		})
+	default:
+		c.Status(http.StatusOK)
+	}
+}
 
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	inp := new(auth.ForgotPasswordInput)
+
+	if !validation.BindJSON(c, inp, "auth") {
 		return
 	}
 
-	if err := h.useCase.SendVerifyCode(c.Request.Context(), inp); err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
+	if err := h.useCase.ForgotPassword(c.Request.Context(), inp); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
 
-func (h *Handler) CheckVerifyCode(c *gin.Context) {
-	inp := new(auth.CheckVerifyCodeInput)
+func (h *Handler) ResetPassword(c *gin.Context) {
+	inp := new(auth.ResetPasswordInput)
 
-	if err := c.BindJSON(inp); err != nil {
-		c.JSON(http.StatusBadRequest, types.BadResponse{
-			Code: http.StatusBadRequest,
-			Error: &types.Error{
-				Message: err.Error(),
-				Field:   "input data",
-				Tag:     "auth",
-			},
-		})
+	if !validation.BindJSON(c, inp, "auth") {
 		return
 	}
 
-	if err := auth.ValidateCheckVerifyCodeInput(inp); err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
+	if err := h.useCase.ResetPassword(c.Request.Context(), inp); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) Refresh(c *gin.Context) {
+	inp := new(auth.RefreshInput)
 
+	if !validation.BindJSON(c, inp, "auth") {
 		return
 	}
 
-	token, err := h.useCase.CheckVerifyCode(c.Request.Context(), inp)
+	tokens, err := h.useCase.Refresh(c.Request.Context(), inp)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, types.GoodResponse{
 		Code: http.StatusOK,
-		Data: map[string]interface{}{
-			"token": token,
+		Data: TokensResponse{
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
 		},
 	})
 }
@@ -133,37 +145,18 @@ func (h *Handler) CheckVerifyCode(c *gin.Context) {
 func (h *Handler) SignIn(c *gin.Context) {
 	inp := new(auth.SignInInput)
 
-	if err := c.BindJSON(inp); err != nil {
-		c.JSON(http.StatusBadRequest, types.BadResponse{
-			Code: http.StatusBadRequest,
-			Error: &types.Error{
-				Message: err.Error(),
-				Field:   "input data",
-				Tag:     "auth",
-			},
-		})
+	if !validation.BindJSON(c, inp, "auth") {
 		return
 	}
 
-	if err := auth.ValidateSignInInput(inp); err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
-
-		return
-	}
-
-	if err := h.useCase.SignIn(c.Request.Context(), inp); err != nil {
-		c.JSON(http.StatusUnauthorized, types.BadResponse{
-			Code:  http.StatusUnauthorized,
-			Error: err,
-		})
+	res, err := h.useCase.SignIn(c.Request.Context(), inp)
+	if err != nil {
+		c.Error(err)
 
 		return
 	}
 
-	c.Status(http.StatusOK)
+	renderSignInResult(c, res)
 }
 
 func (h *Handler) GetProfile(c *gin.Context) {
@@ -178,18 +171,14 @@ func (h *Handler) GetProfile(c *gin.Context) {
 	user, err := h.useCase.GetProfile(c.Request.Context(), inp)
 
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, types.BadResponse{
-			Code:  http.StatusUnauthorized,
-			Error: err,
-		})
-
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, types.GoodResponse{
 		Code: http.StatusOK,
-		Data: map[string]interface{}{
-			"user": user,
+		Data: ProfileResponse{
+			User: user,
 		},
 	})
 }
@@ -197,46 +186,219 @@ func (h *Handler) GetProfile(c *gin.Context) {
 func (h *Handler) UpdateProfile(c *gin.Context) {
 	inp := new(auth.UpdateProfileInput)
 
-	// c токена вытаскиваем
+	if !validation.BindJSON(c, inp, "auth") {
+		return
+	}
+
+	// c токена вытаскиваем, после разбора тела, чтобы его нельзя было подменить
 	if user, exist := c.Get(auth.CtxUserKey); exist {
 		inp.ID = user.(*models.User).ID
 		inp.Email = user.(*models.User).Email
 	}
 
-	if err := c.BindJSON(inp); err != nil {
-		c.JSON(http.StatusBadRequest, types.BadResponse{
-			Code: http.StatusBadRequest,
-			Error: &types.Error{
-				Message: err.Error(),
-				Field:   "input data",
-				Tag:     "auth",
-			},
-		})
+	if token, exist := c.Get(auth.CtxAccessTokenKey); exist {
+		inp.Token = token.(*auth.AccessToken)
+	}
+
+	token, err := h.useCase.UpdateProfile(c.Request.Context(), inp)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	if err := auth.ValidateUpdateProfileInput(inp); err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
+	c.JSON(http.StatusOK, types.GoodResponse{
+		Code: http.StatusOK,
+		Data: UpdateProfileResponse{
+			Token: token,
+		},
+	})
+}
 
+func (h *Handler) SignOut(c *gin.Context) {
+	token := c.MustGet(auth.CtxAccessTokenKey).(*auth.AccessToken)
+
+	if err := h.useCase.SignOut(c.Request.Context(), token); err != nil {
+		c.Error(err)
 		return
 	}
 
-	token, err := h.useCase.UpdateProfile(c.Request.Context(), inp)
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) SignOutAll(c *gin.Context) {
+	token := c.MustGet(auth.CtxAccessTokenKey).(*auth.AccessToken)
+
+	if err := h.useCase.SignOutAll(c.Request.Context(), token); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// JWKS отдает публичные ключи в стандартном формате, без обертки GoodResponse
+func (h *Handler) JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.useCase.JWKS())
+}
+
+func (h *Handler) ChangePassword(c *gin.Context) {
+	inp := new(auth.ChangePasswordInput)
+
+	if !validation.BindJSON(c, inp, "auth") {
+		return
+	}
+
+	// c токена вытаскиваем
+	if user, exist := c.Get(auth.CtxUserKey); exist {
+		inp.ID = user.(*models.User).ID
+	}
+
+	tokens, err := h.useCase.ChangePassword(c.Request.Context(), inp)
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, types.GoodResponse{
 		Code: http.StatusOK,
-		Data: map[string]interface{}{
-			"token": token,
+		Data: TokensResponse{
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
 		},
 	})
-}
\ No newline at end of file
+}
+
+func (h *Handler) ChangeEmail(c *gin.Context) {
+	inp := new(auth.ChangeEmailInput)
+
+	if !validation.BindJSON(c, inp, "auth") {
+		return
+	}
+
+	// c токена вытаскиваем
+	if user, exist := c.Get(auth.CtxUserKey); exist {
+		inp.ID = user.(*models.User).ID
+	}
+
+	if err := h.useCase.ChangeEmail(c.Request.Context(), inp); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) ConfirmChangeEmail(c *gin.Context) {
+	inp := new(auth.ConfirmChangeEmailInput)
+
+	if !validation.BindJSON(c, inp, "auth") {
+		return
+	}
+
+	// c токена вытаскиваем
+	if user, exist := c.Get(auth.CtxUserKey); exist {
+		inp.ID = user.(*models.User).ID
+	}
+
+	if err := h.useCase.ConfirmChangeEmail(c.Request.Context(), inp); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) EnrollTwoFactor(c *gin.Context) {
+	inp := new(auth.EnrollTwoFactorInput)
+
+	if !validation.BindJSON(c, inp, "auth") {
+		return
+	}
+
+	// c токена вытаскиваем
+	if user, exist := c.Get(auth.CtxUserKey); exist {
+		inp.ID = user.(*models.User).ID
+	}
+
+	enrollment, err := h.useCase.EnrollTwoFactor(c.Request.Context(), inp)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.GoodResponse{
+		Code: http.StatusOK,
+		Data: TwoFactorEnrollmentResponse{
+			Secret: enrollment.Secret,
+			URI:    enrollment.URI,
+		},
+	})
+}
+
+func (h *Handler) ConfirmTwoFactor(c *gin.Context) {
+	inp := new(auth.ConfirmTwoFactorInput)
+
+	if !validation.BindJSON(c, inp, "auth") {
+		return
+	}
+
+	// c токена вытаскиваем
+	if user, exist := c.Get(auth.CtxUserKey); exist {
+		inp.ID = user.(*models.User).ID
+	}
+
+	recoveryCodes, err := h.useCase.ConfirmTwoFactor(c.Request.Context(), inp)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.GoodResponse{
+		Code: http.StatusOK,
+		Data: RecoveryCodesResponse{
+			RecoveryCodes: recoveryCodes,
+		},
+	})
+}
+
+func (h *Handler) DisableTwoFactor(c *gin.Context) {
+	inp := new(auth.DisableTwoFactorInput)
+
+	if !validation.BindJSON(c, inp, "auth") {
+		return
+	}
+
+	// c токена вытаскиваем
+	if user, exist := c.Get(auth.CtxUserKey); exist {
+		inp.ID = user.(*models.User).ID
+	}
+
+	if err := h.useCase.DisableTwoFactor(c.Request.Context(), inp); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) VerifyTwoFactor(c *gin.Context) {
+	inp := new(auth.VerifyTwoFactorInput)
+
+	if !validation.BindJSON(c, inp, "auth") {
+		return
+	}
+
+	tokens, err := h.useCase.VerifyTwoFactor(c.Request.Context(), inp)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.GoodResponse{
+		Code: http.StatusOK,
+		Data: TokensResponse{
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+		},
+	})
+}
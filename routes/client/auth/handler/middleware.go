@@ -2,8 +2,7 @@ package authHandler
 
 import (
 	"health/routes/client/auth"
-	"health/shared/types"
-	"net/http"
+	"health/services/i18n"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,19 +21,31 @@ func (m *Middleware) Handle(c *gin.Context) {
 	tokenFromHeader := c.GetHeader("Authorization")
 
 	if tokenFromHeader == "" {
-		c.AbortWithStatus(http.StatusUnauthorized)
+		c.Error(&auth.ErrUserIsUnauthorized)
+		c.Abort()
 		return
 	}
 
-	user, err := m.usecase.ParseToken(c.Request.Context(), tokenFromHeader)
+	token, err := m.usecase.ParseToken(c.Request.Context(), tokenFromHeader)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, &types.BadResponse{
-			Code:  http.StatusUnauthorized,
-			Error: err,
-		})
+		c.Error(err)
+		c.Abort()
+		return
+	}
 
+	// * В токене только id юзера, актуального юзера с ролями берем из базы
+	user, err := m.usecase.GetUserByToken(c.Request.Context(), token)
+	if err != nil {
+		c.Error(err)
+		c.Abort()
 		return
 	}
 
+	// * Выбранный в профиле язык важнее Accept-Language
+	if user.Locale != "" {
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), i18n.Preferred(c.Request.Context(), user.Locale)))
+	}
+
 	c.Set(auth.CtxUserKey, user)
+	c.Set(auth.CtxAccessTokenKey, token)
 }
@@ -0,0 +1,93 @@
+package authHandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"health/routes/client/auth"
+	"health/services/ratelimit"
+	"health/shared/types"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPeekBody — тела /auth/v1 маленькие, больше этого лимитер не читает и отвечает invalid_input
+const maxPeekBody = 64 << 10
+
+type RateLimiter struct {
+	store   ratelimit.Store
+	byIP    ratelimit.Limit
+	byEmail ratelimit.Limit
+}
+
+// NewRateLimitMiddleware ограничивает запросы с одного IP на всю группу
+// и запросы на один email в рамках одного эндпоинта, чтобы нельзя было заспамить чужой ящик
+func NewRateLimitMiddleware(store ratelimit.Store, byIP ratelimit.Limit, byEmail ratelimit.Limit) gin.HandlerFunc {
+	return (&RateLimiter{
+		store:   store,
+		byIP:    byIP,
+		byEmail: byEmail,
+	}).Handle
+}
+
+func (r *RateLimiter) Handle(c *gin.Context) {
+	if !r.allow(c, "ip:"+c.ClientIP(), r.byIP) {
+		return
+	}
+
+	email, err := peekEmail(c)
+	if err != nil {
+		c.Error(types.InvalidInput(err, "auth"))
+		c.Abort()
+		return
+	}
+
+	if email != "" {
+		if !r.allow(c, "email:"+c.Request.URL.Path+":"+email, r.byEmail) {
+			return
+		}
+	}
+}
+
+func (r *RateLimiter) allow(c *gin.Context, key string, limit ratelimit.Limit) bool {
+	allowed, retryAfter, err := r.store.Allow(c.Request.Context(), key, limit)
+	if err != nil {
+		// * Лимитер не должен ронять авторизацию, если хранилище недоступно
+		log.Printf("Rate limiter error: %s", err.Error())
+		return true
+	}
+
+	if !allowed {
+		c.Error(auth.ErrTooManyRequests.WithRetryAfter(retryAfter))
+		c.Abort()
+		return false
+	}
+
+	return true
+}
+
+// peekEmail достает email из JSON тела и возвращает тело на место для хендлера.
+// Тело читается до хендлера и без авторизации, поэтому его размер ограничен.
+func peekEmail(c *gin.Context) (string, error) {
+	if c.Request.Body == nil || c.Request.Method == http.MethodGet {
+		return "", nil
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPeekBody))
+	if err != nil {
+		return "", err
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	inp := struct {
+		Email string `json:"email"`
+	}{}
+	if err := json.Unmarshal(body, &inp); err != nil {
+		return "", nil
+	}
+
+	return strings.ToLower(strings.TrimSpace(inp.Email)), nil
+}
@@ -1,52 +1,56 @@
 package authHandler
 
 import (
-	"health/routes/client/auth/repository"
-	"health/routes/client/auth/usecase"
-	roleRepository "health/routes/client/role/repository"
-	userRoleRepository "health/routes/client/userRole/repository"
-	"health/services/email"
+	"health/models"
+	"health/routes/client/auth"
+	roleHandler "health/routes/client/role/handler"
 
 	"github.com/gin-gonic/gin"
-	"github.com/spf13/viper"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func RegisterHTTPEndpoints(router *gin.Engine, db *mongo.Database, mailer *email.Mailer) gin.HandlerFunc {
-	// Создаем repository, все взаимодействия с db в ней
-	repo := repository.NewRepository(db)
-	roleRepository := roleRepository.NewRepository(db)
-	userRoleRepository := userRoleRepository.NewRepository(db)
-
-	// Создаем usecase, вся бизнес-логика в нем
-	uc := usecase.NewUseCase(
-		repo,
-		roleRepository,
-		userRoleRepository,
+type Module struct {
+	handler        *Handler
+	authMiddleware gin.HandlerFunc
+	rateLimit      gin.HandlerFunc
+}
 
-		mailer,
-		[]byte(viper.GetString("auth.signing_key")),
-		viper.GetDuration("auth.token_ttl"),
-	)
+// NewModule — authMiddleware и rateLimit создает контейнер, тот же authMiddleware получают остальные модули
+func NewModule(uc auth.UseCase, authMiddleware gin.HandlerFunc, rateLimit gin.HandlerFunc) *Module {
+	return &Module{
+		handler:        NewHandler(uc),
+		authMiddleware: authMiddleware,
+		rateLimit:      rateLimit,
+	}
+}
 
-	// Create the middleware instance
-	m := NewMiddleware(uc)
+func (m *Module) RegisterHTTPEndpoints(router *gin.RouterGroup) {
+	h, authMiddleware := m.handler, m.authMiddleware
 
-	// Create the handler
-	h := NewHandler(uc)
+	// Публичные ключи для сервисов, которые проверяют наши токены
+	router.GET("/.well-known/jwks.json", h.JWKS)
 
 	// Create the endpoints
-	endpoints := router.Group("/auth/v1")
+	endpoints := router.Group("/auth/v1", m.rateLimit)
 	{
 		endpoints.POST("/sign-up", h.SignUp)
 		endpoints.POST("/send-verify-code", h.SendVerifyCode)
 		endpoints.POST("/check-verify-code", h.CheckVerifyCode)
 		endpoints.POST("/sign-in", h.SignIn)
+		endpoints.POST("/refresh", h.Refresh)
+		endpoints.POST("/forgot-password", h.ForgotPassword)
+		endpoints.POST("/reset-password", h.ResetPassword)
+		endpoints.POST("/2fa/verify", h.VerifyTwoFactor)
 
 		// * проверяем на наличие аутентификации
-		endpoints.POST("/update-profile", m, h.UpdateProfile)
-		endpoints.GET("/get-profile", m, h.GetProfile)
+		endpoints.POST("/update-profile", authMiddleware, roleHandler.RequirePermission(models.PermissionProfileWrite), h.UpdateProfile)
+		endpoints.GET("/get-profile", authMiddleware, roleHandler.RequirePermission(models.PermissionProfileRead), h.GetProfile)
+		endpoints.POST("/sign-out", authMiddleware, h.SignOut)
+		endpoints.POST("/sign-out-all", authMiddleware, h.SignOutAll)
+		endpoints.POST("/change-password", authMiddleware, h.ChangePassword)
+		endpoints.POST("/change-email", authMiddleware, h.ChangeEmail)
+		endpoints.POST("/change-email/confirm", authMiddleware, h.ConfirmChangeEmail)
+		endpoints.POST("/2fa/enroll", authMiddleware, h.EnrollTwoFactor)
+		endpoints.POST("/2fa/confirm", authMiddleware, h.ConfirmTwoFactor)
+		endpoints.POST("/2fa/disable", authMiddleware, h.DisableTwoFactor)
 	}
-
-	return m
 }
@@ -0,0 +1,36 @@
+package authHandler
+
+import "health/models"
+
+// TokensResponse — пара токенов после входа, обновления или смены пароля
+type TokensResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// SignInResponse — либо пара токенов, либо MFA токен, если вход нужно подтвердить кодом 2FA
+type SignInResponse struct {
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	MFARequired  bool   `json:"mfaRequired,omitempty"`
+	MFAToken     string `json:"mfaToken,omitempty"`
+}
+
+type ProfileResponse struct {
+	User *models.User `json:"user"`
+}
+
+// UpdateProfileResponse — новый access токен, старый после обновления профиля отзывается
+type UpdateProfileResponse struct {
+	Token string `json:"token"`
+}
+
+type TwoFactorEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RecoveryCodesResponse — коды восстановления 2FA, показываются один раз
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
@@ -2,7 +2,16 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"health/models"
+	"time"
+)
+
+var (
+	// ErrEmailIsTaken — CreateUser/UpdateUser нарушили уникальный индекс email
+	ErrEmailIsTaken = errors.New("email is taken")
+	// ErrIINIsTaken — UpdateUser нарушил уникальный индекс ИИН
+	ErrIINIsTaken = errors.New("IIN is taken")
 )
 
 type Repository interface {
@@ -11,4 +20,59 @@ type Repository interface {
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	GetUserById(ctx context.Context, id string) (*models.User, error)
 	DeleteUser(ctx context.Context, id string) error
+	// ListUsers возвращает страницу юзеров по фильтру и общее их число
+	ListUsers(ctx context.Context, filter *UserFilter) ([]*models.User, int64, error)
+
+	// RecordFailedSignIn атомарно увеличивает счетчик неверных паролей, остальные поля юзера не трогает.
+	// lockUntil получает новое число попыток и возвращает конец блокировки, нулевое время — без блокировки.
+	// Блокировка только продлевается, более поздний срок от параллельного запроса не сокращается.
+	RecordFailedSignIn(ctx context.Context, id string, lockUntil func(failedAttempts int) time.Time) (*models.SignInLock, error)
+	// ResetSignInLock обнуляет счетчик неверных паролей и блокировку
+	ResetSignInLock(ctx context.Context, id string) error
+
+	// RecordVerifyCodeAttempt атомарно засчитывает попытку ввода кода field, пока у него прежний hash
+	// и попыток меньше maxAttempts. Попытка, которая доводит счетчик до maxAttempts, блокирует код до lockedUntil.
+	// Возвращает номер попытки, 0 — попытка не засчитана: код сменили или попытки кончились.
+	RecordVerifyCodeAttempt(ctx context.Context, id string, field VerifyCodeField, hash string, maxAttempts int, lockedUntil time.Time) (int, error)
+}
+
+// VerifyCodeField — какой из кодов юзера проверяется
+type VerifyCodeField int
+
+const (
+	// VerifyCodeEmail — код подтверждения email и входа по коду, User.VerifyCode
+	VerifyCodeEmail VerifyCodeField = iota
+	// VerifyCodeEmailChange — код с нового адреса, User.EmailChange.Code
+	VerifyCodeEmailChange
+)
+
+type UserFilter struct {
+	Email                string // подстрока, без учета регистра
+	Verified             *bool
+	FinishedRegistration *bool
+	Suspended            *bool
+	// IDs — если не nil, только эти юзеры
+	IDs []string
+
+	SortBy   string // поле в базе
+	SortDesc bool
+	Skip     int64
+	Limit    int64
+}
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSessionByTokenHash(ctx context.Context, tokenHash string) (*models.Session, error)
+	GetSessionsByUserID(ctx context.Context, userID string) ([]*models.Session, error)
+	// SetSessionAccessTokenID записывает jti нового access токена в активную сессию семьи
+	SetSessionAccessTokenID(ctx context.Context, familyID string, tokenID string) error
+	// RotateSession помечает сессию использованной, false — если ее уже ротировали или отозвали
+	RotateSession(ctx context.Context, id string) (bool, error)
+	RevokeSessionFamily(ctx context.Context, familyID string) error
+	RevokeUserSessions(ctx context.Context, userID string) error
+}
+
+type RevocationRepository interface {
+	RevokeToken(ctx context.Context, token *models.RevokedToken) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
 }
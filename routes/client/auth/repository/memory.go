@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"health/models"
+	"health/routes/client/auth"
+	"health/shared/errs"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryRepository — юзеры в памяти процесса, для локальной разработки без монги.
+// Ошибки те же, что у монги: ненайденный юзер — errs.ErrNotFound, кривой id — ошибка парсинга ObjectID.
+type MemoryRepository struct {
+	mu    sync.RWMutex
+	users map[string]*models.User
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		users: make(map[string]*models.User),
+	}
+}
+
+func (r *MemoryRepository) CreateUser(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.findByEmail(user.Email) != nil {
+		return auth.ErrEmailIsTaken
+	}
+	if r.findByIIN(user.IIN) != nil {
+		return auth.ErrIINIsTaken
+	}
+
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+
+	// * id может быть сгенерирован заранее, на него уже ссылаются user.roles
+	if _, err := primitive.ObjectIDFromHex(user.ID); err != nil {
+		user.ID = primitive.NewObjectID().Hex()
+	}
+
+	r.users[user.ID] = copyUser(user)
+
+	return nil
+}
+
+func (r *MemoryRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	if _, err := primitive.ObjectIDFromHex(user.ID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.ID]
+	if !ok {
+		// * как UpdateOne без совпадений
+		return nil
+	}
+
+	if other := r.findByEmail(user.Email); other != nil && other.ID != user.ID {
+		return auth.ErrEmailIsTaken
+	}
+	if other := r.findByIIN(user.IIN); other != nil && other.ID != user.ID {
+		return auth.ErrIINIsTaken
+	}
+
+	user.UpdatedAt = time.Now()
+
+	updated := copyUser(user)
+	updated.CreatedAt = stored.CreatedAt
+	r.users[user.ID] = updated
+
+	return nil
+}
+
+func (r *MemoryRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user := r.findByEmail(email)
+	if user == nil {
+		return nil, errs.ErrNotFound
+	}
+
+	return copyUser(user), nil
+}
+
+func (r *MemoryRepository) GetUserById(ctx context.Context, id string) (*models.User, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errs.ErrNotFound
+	}
+
+	return copyUser(user), nil
+}
+
+func (r *MemoryRepository) DeleteUser(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, id)
+
+	return nil
+}
+
+func (r *MemoryRepository) ListUsers(ctx context.Context, inp *auth.UserFilter) ([]*models.User, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids map[string]bool
+	if inp.IDs != nil {
+		ids = make(map[string]bool, len(inp.IDs))
+		for _, id := range inp.IDs {
+			ids[id] = true
+		}
+	}
+
+	matched := []*models.User{}
+	for _, user := range r.users {
+		if inp.Email != "" && !strings.Contains(strings.ToLower(user.Email), strings.ToLower(inp.Email)) {
+			continue
+		}
+		if inp.Verified != nil && user.Verified != *inp.Verified {
+			continue
+		}
+		if inp.FinishedRegistration != nil && user.FinishedRegistration != *inp.FinishedRegistration {
+			continue
+		}
+		if inp.Suspended != nil && user.Suspended != *inp.Suspended {
+			continue
+		}
+		if ids != nil && !ids[user.ID] {
+			continue
+		}
+
+		matched = append(matched, user)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if inp.SortDesc {
+			return lessUser(matched[j], matched[i], inp.SortBy)
+		}
+
+		return lessUser(matched[i], matched[j], inp.SortBy)
+	})
+
+	total := int64(len(matched))
+
+	users := []*models.User{}
+	for i := inp.Skip; i < total; i++ {
+		if inp.Limit > 0 && int64(len(users)) >= inp.Limit {
+			break
+		}
+
+		users = append(users, copyUser(matched[i]))
+	}
+
+	return users, total, nil
+}
+
+func (r *MemoryRepository) RecordFailedSignIn(ctx context.Context, id string, lockUntil func(failedAttempts int) time.Time) (*models.SignInLock, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errs.ErrNotFound
+	}
+
+	lock := &user.SignInLock
+	lock.FailedAttempts++
+	if until := lockUntil(lock.FailedAttempts); until.After(lock.LockedUntil) {
+		lock.LockedUntil = until
+	}
+
+	result := *lock
+
+	return &result, nil
+}
+
+func (r *MemoryRepository) ResetSignInLock(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, ok := r.users[id]; ok {
+		user.SignInLock = models.SignInLock{}
+	}
+
+	return nil
+}
+
+func (r *MemoryRepository) RecordVerifyCodeAttempt(ctx context.Context, id string, field auth.VerifyCodeField, hash string, maxAttempts int, lockedUntil time.Time) (int, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return 0, nil
+	}
+
+	code := &user.VerifyCode
+	if field == auth.VerifyCodeEmailChange {
+		code = &user.EmailChange.Code
+	}
+
+	if code.Hash != hash || code.Attempts >= maxAttempts {
+		return 0, nil
+	}
+
+	code.Attempts++
+	if code.Attempts >= maxAttempts {
+		code.LockedUntil = lockedUntil
+	}
+
+	return code.Attempts, nil
+}
+
+func (r *MemoryRepository) findByEmail(email string) *models.User {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user
+		}
+	}
+
+	return nil
+}
+
+// findByIIN — пустой ИИН у незаконченной регистрации уникальным не считается, как частичный индекс
+func (r *MemoryRepository) findByIIN(iin string) *models.User {
+	if iin == "" {
+		return nil
+	}
+
+	for _, user := range r.users {
+		if user.IIN == iin {
+			return user
+		}
+	}
+
+	return nil
+}
+
+// lessUser сортирует по полю из базы, как ListUsers у монги, при равенстве — по id
+func lessUser(a *models.User, b *models.User, sortBy string) bool {
+	switch sortBy {
+	case "email":
+		if a.Email != b.Email {
+			return a.Email < b.Email
+		}
+	case "updated_at":
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		}
+	default:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+	}
+
+	return a.ID < b.ID
+}
+
+// copyUser — наружу отдаем копии, чтобы изменения юзера не попадали в хранилище без UpdateUser
+func copyUser(u *models.User) *models.User {
+	user := *u
+
+	user.UserRoleIDs = append([]string{}, u.UserRoleIDs...)
+	user.TwoFactor.RecoveryCodes = append([]string{}, u.TwoFactor.RecoveryCodes...)
+	// * роли с названиями собирает usecase, в базе их нет
+	user.UserRoles = nil
+
+	return &user
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"health/models"
+	"sync"
+	"time"
+)
+
+type MemoryRevocationRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]*models.RevokedToken
+}
+
+func NewMemoryRevocationRepository() *MemoryRevocationRepository {
+	return &MemoryRevocationRepository{
+		tokens: make(map[string]*models.RevokedToken),
+	}
+}
+
+func (r *MemoryRevocationRepository) RevokeToken(ctx context.Context, token *models.RevokedToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// * Повторный отзыв того же jti не должен плодить записи
+	if _, ok := r.tokens[token.TokenID]; ok {
+		return nil
+	}
+
+	// * Истекшие токены уже не пройдут проверку подписи, в монге их убирает TTL индекс
+	for id, stored := range r.tokens {
+		if stored.ExpiresAt.Before(time.Now()) {
+			delete(r.tokens, id)
+		}
+	}
+
+	token.CreatedAt = time.Now()
+
+	stored := *token
+	r.tokens[token.TokenID] = &stored
+
+	return nil
+}
+
+func (r *MemoryRevocationRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.tokens[tokenID]
+
+	return ok, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"health/models"
+	"health/shared/errs"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MemorySessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]*models.Session
+}
+
+func NewMemorySessionRepository() *MemorySessionRepository {
+	return &MemorySessionRepository{
+		sessions: make(map[string]*models.Session),
+	}
+}
+
+func (r *MemorySessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// * Истекшие сессии убираем сами, в монге это делает TTL индекс
+	for id, stored := range r.sessions {
+		if stored.ExpiresAt.Before(time.Now()) {
+			delete(r.sessions, id)
+		}
+	}
+
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = time.Now()
+	session.ID = primitive.NewObjectID().Hex()
+
+	stored := *session
+	r.sessions[session.ID] = &stored
+
+	return nil
+}
+
+func (r *MemorySessionRepository) GetSessionByTokenHash(ctx context.Context, tokenHash string) (*models.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, session := range r.sessions {
+		if session.TokenHash == tokenHash {
+			found := *session
+			return &found, nil
+		}
+	}
+
+	return nil, errs.ErrNotFound
+}
+
+func (r *MemorySessionRepository) GetSessionsByUserID(ctx context.Context, userID string) ([]*models.Session, error) {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sessions []*models.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			found := *session
+			sessions = append(sessions, &found)
+		}
+	}
+
+	return sessions, nil
+}
+
+func (r *MemorySessionRepository) SetSessionAccessTokenID(ctx context.Context, familyID string, tokenID string) error {
+	if _, err := primitive.ObjectIDFromHex(familyID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// * как UpdateOne: только одна активная сессия семьи
+	for _, session := range r.sessions {
+		if session.FamilyID == familyID && !session.Rotated && !session.Revoked {
+			session.AccessTokenID = tokenID
+			session.UpdatedAt = time.Now()
+			break
+		}
+	}
+
+	return nil
+}
+
+func (r *MemorySessionRepository) RotateSession(ctx context.Context, id string) (bool, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.Rotated || session.Revoked {
+		return false, nil
+	}
+
+	session.Rotated = true
+	session.UpdatedAt = time.Now()
+
+	return true, nil
+}
+
+func (r *MemorySessionRepository) RevokeSessionFamily(ctx context.Context, familyID string) error {
+	if _, err := primitive.ObjectIDFromHex(familyID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, session := range r.sessions {
+		if session.FamilyID == familyID {
+			session.Revoked = true
+			session.UpdatedAt = time.Now()
+		}
+	}
+
+	return nil
+}
+
+func (r *MemorySessionRepository) RevokeUserSessions(ctx context.Context, userID string) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, session := range r.sessions {
+		if session.UserID == userID && !session.Revoked {
+			session.Revoked = true
+			session.UpdatedAt = time.Now()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,388 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"health/models"
+	"health/routes/client/auth"
+	"health/services/transaction"
+	"health/shared/utils"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const userColumns = `id, email, password, password_confirm, verified, verify_code, email_change, two_factor, sign_in_lock,
+	suspended, suspended_at, user_role_ids, roles_version, finished_registration,
+	iin, name, surname, birthday, gender, address, locale, created_at, updated_at`
+
+// PostgresRepository — юзеры в таблице users, вложенные объекты лежат в jsonb
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresRepository) CreateUser(ctx context.Context, user *models.User) error {
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+
+	// * id может быть сгенерирован заранее, на него уже ссылаются user.roles
+	if _, err := primitive.ObjectIDFromHex(user.ID); err != nil {
+		user.ID = primitive.NewObjectID().Hex()
+	}
+
+	args, err := userArgs(user)
+	if err != nil {
+		return err
+	}
+
+	_, err = transaction.SQLExecutor(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO users (`+userColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)`,
+		args...,
+	)
+	if utils.IsUniqueViolation(err) {
+		return duplicatePostgresUserError(err)
+	}
+
+	return err
+}
+
+func (r *PostgresRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	if _, err := primitive.ObjectIDFromHex(user.ID); err != nil {
+		return err
+	}
+
+	user.UpdatedAt = time.Now()
+
+	args, err := userArgs(user)
+	if err != nil {
+		return err
+	}
+
+	// * created_at не меняем, в аргументах остается только updated_at
+	args = append(args[:21:21], user.UpdatedAt)
+
+	_, err = transaction.SQLExecutor(ctx, r.db).ExecContext(ctx,
+		`UPDATE users SET
+			email = $2, password = $3, password_confirm = $4, verified = $5,
+			verify_code = $6, email_change = $7, two_factor = $8, sign_in_lock = $9,
+			suspended = $10, suspended_at = $11, user_role_ids = $12, roles_version = $13,
+			finished_registration = $14, iin = $15, name = $16, surname = $17,
+			birthday = $18, gender = $19, address = $20, locale = $21, updated_at = $22
+		WHERE id = $1`,
+		args...,
+	)
+	if utils.IsUniqueViolation(err) {
+		return duplicatePostgresUserError(err)
+	}
+
+	return err
+}
+
+func (r *PostgresRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	row := transaction.SQLExecutor(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+userColumns+` FROM users WHERE email = $1`,
+		email,
+	)
+
+	return scanUser(row)
+}
+
+func (r *PostgresRepository) GetUserById(ctx context.Context, id string) (*models.User, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+
+	row := transaction.SQLExecutor(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+userColumns+` FROM users WHERE id = $1`,
+		id,
+	)
+
+	return scanUser(row)
+}
+
+func (r *PostgresRepository) DeleteUser(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return err
+	}
+
+	_, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+
+	return err
+}
+
+// userSortColumns — поля сортировки ListUsers, в запрос попадают только они
+var userSortColumns = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"email":      "email",
+}
+
+func (r *PostgresRepository) ListUsers(ctx context.Context, inp *auth.UserFilter) ([]*models.User, int64, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if inp.Email != "" {
+		addCondition("strpos(lower(email), lower($%d)) > 0", inp.Email)
+	}
+	if inp.Verified != nil {
+		addCondition("verified = $%d", *inp.Verified)
+	}
+	if inp.FinishedRegistration != nil {
+		addCondition("finished_registration = $%d", *inp.FinishedRegistration)
+	}
+	if inp.Suspended != nil {
+		addCondition("suspended = $%d", *inp.Suspended)
+	}
+	if inp.IDs != nil {
+		addCondition("id = ANY($%d)", pq.Array(inp.IDs))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	executor := transaction.SQLExecutor(ctx, r.db)
+
+	var total int64
+	if err := executor.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	sortBy, ok := userSortColumns[inp.SortBy]
+	if !ok {
+		sortBy = "created_at"
+	}
+	order := "ASC"
+	if inp.SortDesc {
+		order = "DESC"
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM users%s ORDER BY %s %s, id %s OFFSET %d`, userColumns, where, sortBy, order, order, inp.Skip)
+	if inp.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", inp.Limit)
+	}
+
+	rows, err := executor.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *PostgresRepository) RecordFailedSignIn(ctx context.Context, id string, lockUntil func(failedAttempts int) time.Time) (*models.SignInLock, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+
+	executor := transaction.SQLExecutor(ctx, r.db)
+
+	// * Счетчик увеличивает сама база, остальные колонки юзера не трогаем
+	var raw []byte
+	err := executor.QueryRowContext(ctx,
+		`UPDATE users SET sign_in_lock = jsonb_set(sign_in_lock, '{FailedAttempts}',
+			to_jsonb(COALESCE((sign_in_lock->>'FailedAttempts')::int, 0) + 1))
+		WHERE id = $1
+		RETURNING sign_in_lock`,
+		id,
+	).Scan(&raw)
+	if err != nil {
+		return nil, utils.SQLNotFound(err)
+	}
+
+	lock := new(models.SignInLock)
+	if err := json.Unmarshal(raw, lock); err != nil {
+		return nil, err
+	}
+
+	until := lockUntil(lock.FailedAttempts)
+	if until.IsZero() || !until.After(lock.LockedUntil) {
+		return lock, nil
+	}
+
+	// * Как $max у монги: срок, выставленный параллельным запросом, не сокращаем
+	_, err = executor.ExecContext(ctx,
+		`UPDATE users SET sign_in_lock = jsonb_set(sign_in_lock, '{LockedUntil}', to_jsonb($2::text))
+		WHERE id = $1 AND COALESCE((sign_in_lock->>'LockedUntil')::timestamptz, '-infinity') < $3`,
+		id, until.UTC().Format(time.RFC3339Nano), until,
+	)
+	if err != nil {
+		return nil, err
+	}
+	lock.LockedUntil = until
+
+	return lock, nil
+}
+
+func (r *PostgresRepository) ResetSignInLock(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return err
+	}
+
+	_, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx, `UPDATE users SET sign_in_lock = '{}' WHERE id = $1`, id)
+
+	return err
+}
+
+// verifyCodeColumns — колонка и путь кода внутри jsonb, ключи как у json.Marshal(models.User)
+var verifyCodeColumns = map[auth.VerifyCodeField]struct{ column, path string }{
+	auth.VerifyCodeEmail:       {"verify_code", ""},
+	auth.VerifyCodeEmailChange: {"email_change", "Code,"},
+}
+
+func (r *PostgresRepository) RecordVerifyCodeAttempt(ctx context.Context, id string, field auth.VerifyCodeField, hash string, maxAttempts int, lockedUntil time.Time) (int, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return 0, err
+	}
+
+	target := verifyCodeColumns[field]
+	code := target.column
+	if target.path != "" {
+		code += "->'Code'"
+	}
+
+	executor := transaction.SQLExecutor(ctx, r.db)
+
+	// * Лимит проверяет сам WHERE: параллельные попытки не проскочат мимо maxAttempts
+	var attempts int
+	err := executor.QueryRowContext(ctx, fmt.Sprintf(
+		`UPDATE users SET %[1]s = jsonb_set(%[1]s, '{%[2]sAttempts}', to_jsonb(COALESCE((%[3]s->>'Attempts')::int, 0) + 1))
+		WHERE id = $1 AND %[3]s->>'Hash' = $2 AND COALESCE((%[3]s->>'Attempts')::int, 0) < $3
+		RETURNING (%[3]s->>'Attempts')::int`,
+		target.column, target.path, code),
+		id, hash, maxAttempts,
+	).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if attempts >= maxAttempts {
+		_, err = executor.ExecContext(ctx, fmt.Sprintf(
+			`UPDATE users SET %[1]s = jsonb_set(%[1]s, '{%[2]sLockedUntil}', to_jsonb($3::text))
+			WHERE id = $1 AND %[3]s->>'Hash' = $2`,
+			target.column, target.path, code),
+			id, hash, lockedUntil.UTC().Format(time.RFC3339Nano),
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return attempts, nil
+}
+
+// userArgs — значения в порядке userColumns
+func userArgs(u *models.User) ([]interface{}, error) {
+	verifyCode, err := json.Marshal(u.VerifyCode)
+	if err != nil {
+		return nil, err
+	}
+	emailChange, err := json.Marshal(u.EmailChange)
+	if err != nil {
+		return nil, err
+	}
+	twoFactor, err := json.Marshal(u.TwoFactor)
+	if err != nil {
+		return nil, err
+	}
+	signInLock, err := json.Marshal(u.SignInLock)
+	if err != nil {
+		return nil, err
+	}
+	address, err := json.Marshal(u.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	userRoleIDs := u.UserRoleIDs
+	if userRoleIDs == nil {
+		userRoleIDs = []string{}
+	}
+
+	return []interface{}{
+		u.ID, u.Email, u.Password, u.PasswordConfirm, u.Verified,
+		verifyCode, emailChange, twoFactor, signInLock,
+		u.Suspended, u.SuspendedAt, pq.Array(userRoleIDs), u.RolesVersion, u.FinishedRegistration,
+		u.IIN, u.Name, u.Surname, u.Birthday, u.Gender, address, u.Locale, u.CreatedAt, u.UpdatedAt,
+	}, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row rowScanner) (*models.User, error) {
+	user := new(models.User)
+
+	var verifyCode, emailChange, twoFactor, signInLock, address []byte
+	err := row.Scan(
+		&user.ID, &user.Email, &user.Password, &user.PasswordConfirm, &user.Verified,
+		&verifyCode, &emailChange, &twoFactor, &signInLock,
+		&user.Suspended, &user.SuspendedAt, pq.Array(&user.UserRoleIDs), &user.RolesVersion, &user.FinishedRegistration,
+		&user.IIN, &user.Name, &user.Surname, &user.Birthday, &user.Gender, &address, &user.Locale, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, utils.SQLNotFound(err)
+	}
+
+	jsonFields := []struct {
+		raw    []byte
+		target interface{}
+	}{
+		{verifyCode, &user.VerifyCode},
+		{emailChange, &user.EmailChange},
+		{twoFactor, &user.TwoFactor},
+		{signInLock, &user.SignInLock},
+		{address, &user.Address},
+	}
+	for _, field := range jsonFields {
+		if err := json.Unmarshal(field.raw, field.target); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// duplicatePostgresUserError — имена индексов из migrations/postgres
+func duplicatePostgresUserError(err error) error {
+	if utils.IsUniqueViolationOn(err, "users_iin_unique") {
+		return auth.ErrIINIsTaken
+	}
+
+	return auth.ErrEmailIsTaken
+}
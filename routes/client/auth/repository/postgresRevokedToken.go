@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"health/models"
+	"health/services/transaction"
+	"time"
+)
+
+type PostgresRevocationRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRevocationRepository(db *sql.DB) *PostgresRevocationRepository {
+	return &PostgresRevocationRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresRevocationRepository) RevokeToken(ctx context.Context, token *models.RevokedToken) error {
+	executor := transaction.SQLExecutor(ctx, r.db)
+
+	// * Истекшие токены уже не пройдут проверку подписи, TTL индексов нет, убираем сами
+	if _, err := executor.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, time.Now()); err != nil {
+		return err
+	}
+
+	token.CreatedAt = time.Now()
+
+	// * Повторный отзыв того же jti не должен плодить записи
+	_, err := executor.ExecContext(ctx,
+		`INSERT INTO revoked_tokens (token_id, user_id, expires_at, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (token_id) DO NOTHING`,
+		token.TokenID, token.UserID, token.ExpiresAt, token.CreatedAt,
+	)
+
+	return err
+}
+
+func (r *PostgresRevocationRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var revoked bool
+
+	err := transaction.SQLExecutor(ctx, r.db).QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE token_id = $1)`,
+		tokenID,
+	).Scan(&revoked)
+
+	return revoked, err
+}
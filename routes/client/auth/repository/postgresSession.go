@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"health/models"
+	"health/services/transaction"
+	"health/shared/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const sessionColumns = `id, user_id, family_id, token_hash, access_token_id, rotated, revoked, expires_at, created_at, updated_at`
+
+type PostgresSessionRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresSessionRepository(db *sql.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresSessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	executor := transaction.SQLExecutor(ctx, r.db)
+
+	// * TTL индексов нет, истекшие сессии убираем сами
+	if _, err := executor.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at < $1`, time.Now()); err != nil {
+		return err
+	}
+
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = time.Now()
+	session.ID = primitive.NewObjectID().Hex()
+
+	_, err := executor.ExecContext(ctx,
+		`INSERT INTO sessions (`+sessionColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		session.ID, session.UserID, session.FamilyID, session.TokenHash, session.AccessTokenID,
+		session.Rotated, session.Revoked, session.ExpiresAt, session.CreatedAt, session.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *PostgresSessionRepository) GetSessionByTokenHash(ctx context.Context, tokenHash string) (*models.Session, error) {
+	row := transaction.SQLExecutor(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+sessionColumns+` FROM sessions WHERE token_hash = $1`,
+		tokenHash,
+	)
+
+	return scanSession(row)
+}
+
+func (r *PostgresSessionRepository) GetSessionsByUserID(ctx context.Context, userID string) ([]*models.Session, error) {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := transaction.SQLExecutor(ctx, r.db).QueryContext(ctx,
+		`SELECT `+sessionColumns+` FROM sessions WHERE user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *PostgresSessionRepository) SetSessionAccessTokenID(ctx context.Context, familyID string, tokenID string) error {
+	if _, err := primitive.ObjectIDFromHex(familyID); err != nil {
+		return err
+	}
+
+	_, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx,
+		`UPDATE sessions SET access_token_id = $2, updated_at = $3
+		WHERE family_id = $1 AND NOT rotated AND NOT revoked`,
+		familyID, tokenID, time.Now(),
+	)
+
+	return err
+}
+
+func (r *PostgresSessionRepository) RotateSession(ctx context.Context, id string) (bool, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return false, err
+	}
+
+	// * Обновляем только еще не использованную сессию, чтобы два параллельных refresh не прошли оба
+	res, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx,
+		`UPDATE sessions SET rotated = TRUE, updated_at = $2
+		WHERE id = $1 AND NOT rotated AND NOT revoked`,
+		id, time.Now(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *PostgresSessionRepository) RevokeSessionFamily(ctx context.Context, familyID string) error {
+	if _, err := primitive.ObjectIDFromHex(familyID); err != nil {
+		return err
+	}
+
+	_, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx,
+		`UPDATE sessions SET revoked = TRUE, updated_at = $2 WHERE family_id = $1`,
+		familyID, time.Now(),
+	)
+
+	return err
+}
+
+func (r *PostgresSessionRepository) RevokeUserSessions(ctx context.Context, userID string) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return err
+	}
+
+	_, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx,
+		`UPDATE sessions SET revoked = TRUE, updated_at = $2 WHERE user_id = $1 AND NOT revoked`,
+		userID, time.Now(),
+	)
+
+	return err
+}
+
+func scanSession(row rowScanner) (*models.Session, error) {
+	session := new(models.Session)
+
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.FamilyID, &session.TokenHash, &session.AccessTokenID,
+		&session.Rotated, &session.Revoked, &session.ExpiresAt, &session.CreatedAt, &session.UpdatedAt,
+	)
+	if err != nil {
+		return nil, utils.SQLNotFound(err)
+	}
+
+	return session, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"health/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RevocationRepository struct {
+	*mongo.Collection
+}
+
+func NewRevocationRepository(db *mongo.Database) *RevocationRepository {
+	return &RevocationRepository{
+		Collection: db.Collection(models.RevokedTokenCollection),
+	}
+}
+
+func (r *RevocationRepository) RevokeToken(ctx context.Context, token *models.RevokedToken) error {
+	token.CreatedAt = time.Now()
+
+	model := mapRevokedTokenToMongoSchema(token)
+
+	// * Повторный отзыв того же jti не должен плодить записи
+	filter := bson.M{
+		"tokenId": model.TokenID,
+	}
+	update := bson.M{
+		"$setOnInsert": model,
+	}
+	_, err := r.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RevocationRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	count, err := r.CountDocuments(ctx, bson.M{"tokenId": tokenID})
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func mapRevokedTokenToMongoSchema(t *models.RevokedToken) *models.RevokedTokenDBSchema {
+	userOid, _ := primitive.ObjectIDFromHex(t.UserID)
+
+	return &models.RevokedTokenDBSchema{
+		TokenID: t.TokenID,
+		UserID:  userOid,
+
+		ExpiresAt: t.ExpiresAt,
+		CreatedAt: t.CreatedAt,
+	}
+}
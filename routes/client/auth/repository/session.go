@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"health/models"
+	"health/shared/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SessionRepository struct {
+	*mongo.Collection
+}
+
+func NewSessionRepository(db *mongo.Database) *SessionRepository {
+	return &SessionRepository{
+		Collection: db.Collection(models.SessionCollection),
+	}
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = time.Now()
+
+	model := mapSessionToMongoSchema(session)
+	res, err := r.InsertOne(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		session.ID = oid.Hex()
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) GetSessionByTokenHash(ctx context.Context, tokenHash string) (*models.Session, error) {
+	session := new(models.SessionDBSchema)
+
+	filter := bson.M{
+		"tokenHash": tokenHash,
+	}
+
+	if err := r.FindOne(ctx, filter).Decode(session); err != nil {
+		return nil, utils.MongoNotFound(err)
+	}
+
+	return mapSessionToDomainModel(session), nil
+}
+
+func (r *SessionRepository) GetSessionsByUserID(ctx context.Context, userID string) ([]*models.Session, error) {
+	var sessions []*models.Session
+
+	oid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := r.Find(ctx, bson.M{"userId": oid})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		session := new(models.SessionDBSchema)
+		if err := cur.Decode(session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, mapSessionToDomainModel(session))
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *SessionRepository) SetSessionAccessTokenID(ctx context.Context, familyID string, tokenID string) error {
+	oid, err := primitive.ObjectIDFromHex(familyID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{
+		"familyId": oid,
+		"rotated":  false,
+		"revoked":  false,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"accessTokenId": tokenID,
+			"updated_at":    time.Now(),
+		},
+	}
+
+	_, err = r.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RotateSession(ctx context.Context, id string) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, err
+	}
+
+	// * Обновляем только еще не использованную сессию, чтобы два параллельных refresh не прошли оба
+	filter := bson.M{
+		"_id":     oid,
+		"rotated": false,
+		"revoked": false,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"rotated":    true,
+			"updated_at": time.Now(),
+		},
+	}
+
+	res, err := r.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
+func (r *SessionRepository) RevokeSessionFamily(ctx context.Context, familyID string) error {
+	oid, err := primitive.ObjectIDFromHex(familyID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{
+		"familyId": oid,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"revoked":    true,
+			"updated_at": time.Now(),
+		},
+	}
+
+	_, err = r.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeUserSessions(ctx context.Context, userID string) error {
+	oid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{
+		"userId":  oid,
+		"revoked": false,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"revoked":    true,
+			"updated_at": time.Now(),
+		},
+	}
+
+	_, err = r.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func mapSessionToMongoSchema(s *models.Session) *models.SessionDBSchema {
+	userOid, _ := primitive.ObjectIDFromHex(s.UserID)
+	familyOid, _ := primitive.ObjectIDFromHex(s.FamilyID)
+
+	return &models.SessionDBSchema{
+		UserID:        userOid,
+		FamilyID:      familyOid,
+		TokenHash:     s.TokenHash,
+		AccessTokenID: s.AccessTokenID,
+
+		Rotated: s.Rotated,
+		Revoked: s.Revoked,
+
+		ExpiresAt: s.ExpiresAt,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+func mapSessionToDomainModel(s *models.SessionDBSchema) *models.Session {
+	return &models.Session{
+		ID: s.ID.Hex(),
+
+		UserID:        s.UserID.Hex(),
+		FamilyID:      s.FamilyID.Hex(),
+		TokenHash:     s.TokenHash,
+		AccessTokenID: s.AccessTokenID,
+
+		Rotated: s.Rotated,
+		Revoked: s.Revoked,
+
+		ExpiresAt: s.ExpiresAt,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
@@ -3,11 +3,15 @@ package repository
 import (
 	"context"
 	"health/models"
+	"health/routes/client/auth"
+	"health/shared/utils"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Repository struct {
@@ -25,7 +29,15 @@ func (r Repository) CreateUser(ctx context.Context, user *models.User) error {
 	user.UpdatedAt = time.Now()
 
 	model := mapToMongoSchema(user)
+	// * id может быть сгенерирован заранее, на него уже ссылаются user.roles
+	if oid, err := primitive.ObjectIDFromHex(user.ID); err == nil {
+		model.ID = oid
+	}
+
 	res, err := r.InsertOne(ctx, model)
+	if utils.IsDuplicateKeyError(err) {
+		return duplicateUserError(err)
+	}
 	if err != nil {
 		return err
 	}
@@ -38,18 +50,26 @@ func (r Repository) CreateUser(ctx context.Context, user *models.User) error {
 }
 
 func (r Repository) UpdateUser(ctx context.Context, user *models.User) error {
+	oid, err := primitive.ObjectIDFromHex(user.ID)
+	if err != nil {
+		return err
+	}
+
 	user.UpdatedAt = time.Now()
 
 	model := mapToMongoSchema(user)
 
+	// * Ищем по _id, а не по email, иначе email нельзя было бы поменять
 	filter := bson.M{
-		"email": model.Email,
+		"_id": oid,
 	}
 	update := bson.M{
 		"$set": model,
 	}
-	_, err := r.UpdateOne(ctx, filter, update)
-
+	_, err = r.UpdateOne(ctx, filter, update)
+	if utils.IsDuplicateKeyError(err) {
+		return duplicateUserError(err)
+	}
 	if err != nil {
 		return err
 	}
@@ -67,7 +87,7 @@ func (r *Repository) GetUserByEmail(ctx context.Context, email string) (*models.
 	err := r.FindOne(ctx, filter).Decode(user)
 
 	if err != nil {
-		return nil, err
+		return nil, utils.MongoNotFound(err)
 	}
 
 	return mapToDomainModel(user), nil
@@ -83,7 +103,7 @@ func (r *Repository) GetUserById(ctx context.Context, id string) (*models.User,
 	err = r.FindOne(ctx, bson.M{"_id": oid}).Decode(user)
 
 	if err != nil {
-		return nil, err
+		return nil, utils.MongoNotFound(err)
 	}
 
 	return mapToDomainModel(user), nil
@@ -103,6 +123,179 @@ func (r *Repository) DeleteUser(ctx context.Context, id string) error {
 	return nil
 }
 
+func (r *Repository) ListUsers(ctx context.Context, inp *auth.UserFilter) ([]*models.User, int64, error) {
+	users := []*models.User{}
+
+	filter := bson.M{}
+	if inp.Email != "" {
+		filter["email"] = bson.M{
+			"$regex":   regexp.QuoteMeta(inp.Email),
+			"$options": "i",
+		}
+	}
+	if inp.Verified != nil {
+		filter["verified"] = *inp.Verified
+	}
+	if inp.FinishedRegistration != nil {
+		filter["finishedRegistration"] = *inp.FinishedRegistration
+	}
+	if inp.Suspended != nil {
+		filter["suspended"] = *inp.Suspended
+	}
+	if inp.IDs != nil {
+		oids := make([]primitive.ObjectID, 0, len(inp.IDs))
+		for _, id := range inp.IDs {
+			if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+				oids = append(oids, oid)
+			}
+		}
+		filter["_id"] = bson.M{"$in": oids}
+	}
+
+	total, err := r.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sortBy, order := "created_at", 1
+	if inp.SortBy != "" {
+		sortBy = inp.SortBy
+	}
+	if inp.SortDesc {
+		order = -1
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: sortBy, Value: order}, {Key: "_id", Value: order}}).
+		SetSkip(inp.Skip).
+		SetLimit(inp.Limit)
+
+	cur, err := r.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		user := new(models.UserDBSchema)
+		if err := cur.Decode(user); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, mapToDomainModel(user))
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *Repository) RecordFailedSignIn(ctx context.Context, id string, lockUntil func(failedAttempts int) time.Time) (*models.SignInLock, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// * $inc вместо $set всего документа: параллельные ошибки не теряются,
+	// * а пароль, роли и блокировка админом не перезаписываются прочитанными до сверки значениями
+	var res struct {
+		SignInLock models.SignInLockDBSchema `bson:"signInLock"`
+	}
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"signInLock": 1})
+
+	err = r.FindOneAndUpdate(ctx, bson.M{"_id": oid}, bson.M{
+		"$inc": bson.M{"signInLock.failedAttempts": 1},
+	}, opts).Decode(&res)
+	if err != nil {
+		return nil, utils.MongoNotFound(err)
+	}
+
+	lock := models.SignInLock(res.SignInLock)
+
+	until := lockUntil(lock.FailedAttempts)
+	if until.IsZero() || !until.After(lock.LockedUntil) {
+		return &lock, nil
+	}
+
+	_, err = r.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$max": bson.M{"signInLock.locked_until": until},
+	})
+	if err != nil {
+		return nil, err
+	}
+	lock.LockedUntil = until
+
+	return &lock, nil
+}
+
+func (r *Repository) ResetSignInLock(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{
+		"$set": bson.M{"signInLock": models.SignInLockDBSchema{}},
+	})
+
+	return err
+}
+
+// verifyCodePaths — пути кодов в документе юзера, как в UserDBSchema
+var verifyCodePaths = map[auth.VerifyCodeField]string{
+	auth.VerifyCodeEmail:       "verification",
+	auth.VerifyCodeEmailChange: "emailChange.code",
+}
+
+func (r *Repository) RecordVerifyCodeAttempt(ctx context.Context, id string, field auth.VerifyCodeField, hash string, maxAttempts int, lockedUntil time.Time) (int, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, err
+	}
+
+	path := verifyCodePaths[field]
+
+	// * Лимит проверяет сам фильтр: параллельные попытки не проскочат мимо maxAttempts
+	filter := bson.M{
+		"_id":              oid,
+		path + ".hash":     hash,
+		path + ".attempts": bson.M{"$lt": maxAttempts},
+	}
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{path: 1})
+
+	user := new(models.UserDBSchema)
+	err = r.FindOneAndUpdate(ctx, filter, bson.M{
+		"$inc": bson.M{path + ".attempts": 1},
+	}, opts).Decode(user)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	attempts := user.VerifyCode.Attempts
+	if field == auth.VerifyCodeEmailChange {
+		attempts = user.EmailChange.Code.Attempts
+	}
+
+	if attempts >= maxAttempts {
+		_, err = r.UpdateOne(ctx, bson.M{"_id": oid, path + ".hash": hash}, bson.M{
+			"$set": bson.M{path + ".locked_until": lockedUntil},
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return attempts, nil
+}
+
 func mapToMongoSchema(u *models.User) *models.UserDBSchema {
 	rolesLikeID := make([]primitive.ObjectID, len(u.UserRoleIDs))
 	for i, roleID := range u.UserRoleIDs {
@@ -116,7 +309,16 @@ func mapToMongoSchema(u *models.User) *models.UserDBSchema {
 		Password:        u.Password,
 		PasswordConfirm: u.PasswordConfirm,
 		Verified:        u.Verified,
-		VerifyCode:      u.VerifyCode,
+		VerifyCode:      models.VerifyCodeDBSchema(u.VerifyCode),
+		EmailChange: models.EmailChangeDBSchema{
+			Email: u.EmailChange.Email,
+			Code:  models.VerifyCodeDBSchema(u.EmailChange.Code),
+		},
+		TwoFactor:  models.TwoFactorDBSchema(u.TwoFactor),
+		SignInLock: models.SignInLockDBSchema(u.SignInLock),
+
+		Suspended:   u.Suspended,
+		SuspendedAt: u.SuspendedAt,
 
 		FinishedRegistration: u.FinishedRegistration,
 
@@ -127,7 +329,10 @@ func mapToMongoSchema(u *models.User) *models.UserDBSchema {
 		Gender:   u.Gender,
 		Address:  models.AddressDBSchema(u.Address),
 
-		UserRoleIDs: rolesLikeID,
+		Locale: u.Locale,
+
+		UserRoleIDs:  rolesLikeID,
+		RolesVersion: u.RolesVersion,
 
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
@@ -147,7 +352,16 @@ func mapToDomainModel(u *models.UserDBSchema) *models.User {
 		Password:        u.Password,
 		PasswordConfirm: u.PasswordConfirm,
 		Verified:        u.Verified,
-		VerifyCode:      u.VerifyCode,
+		VerifyCode:      models.VerifyCode(u.VerifyCode),
+		EmailChange: models.EmailChange{
+			Email: u.EmailChange.Email,
+			Code:  models.VerifyCode(u.EmailChange.Code),
+		},
+		TwoFactor:  models.TwoFactor(u.TwoFactor),
+		SignInLock: models.SignInLock(u.SignInLock),
+
+		Suspended:   u.Suspended,
+		SuspendedAt: u.SuspendedAt,
 
 		FinishedRegistration: u.FinishedRegistration,
 
@@ -158,9 +372,21 @@ func mapToDomainModel(u *models.UserDBSchema) *models.User {
 		Gender:   u.Gender,
 		Address:  models.Address(u.Address),
 
-		UserRoleIDs: rolesLikeString,
+		Locale: u.Locale,
+
+		UserRoleIDs:  rolesLikeString,
+		RolesVersion: u.RolesVersion,
 
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
 }
+
+// duplicateUserError — какой из уникальных индексов юзера нарушен, имена индексов из migrations
+func duplicateUserError(err error) error {
+	if utils.IsDuplicateKeyOn(err, "IIN_unique") {
+		return auth.ErrIINIsTaken
+	}
+
+	return auth.ErrEmailIsTaken
+}
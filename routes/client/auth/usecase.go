@@ -3,18 +3,73 @@ package auth
 import (
 	"context"
 	"health/models"
+	"health/services/jwk"
 	"health/shared/types"
+	"time"
 )
 
-const CtxUserKey = "user"
+const (
+	CtxUserKey        = "user"
+	CtxAccessTokenKey = "accessToken"
+)
+
+// AccessToken — разобранный и проверенный access token текущего запроса
+type AccessToken struct {
+	ID           string
+	SessionID    string
+	UserID       string
+	RolesVersion int
+	ExpiresAt    time.Time
+}
+
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// SignInResult — либо пара токенов, либо MFAToken, если у юзера включена 2FA
+// и вход нужно подтвердить кодом через VerifyTwoFactor
+type SignInResult struct {
+	Tokens   *Tokens
+	MFAToken string
+}
+
+type TwoFactorEnrollment struct {
+	Secret string
+	URI    string
+}
 
 type UseCase interface {
 	SignUp(ctx context.Context, inp *SignUpInput) *types.Error
 	SendVerifyCode(ctx context.Context, inp *SendVerifyCodeInput) *types.Error
-	CheckVerifyCode(ctx context.Context, inp *CheckVerifyCodeInput) (string, *types.Error)
+	CheckVerifyCode(ctx context.Context, inp *CheckVerifyCodeInput) (*SignInResult, *types.Error)
+
+	SignIn(ctx context.Context, inp *SignInInput) (*SignInResult, *types.Error)
+	ParseToken(ctx context.Context, accessToken string) (*AccessToken, *types.Error)
+	GetUserByToken(ctx context.Context, token *AccessToken) (*models.User, *types.Error)
+	JWKS() *jwk.JSONWebKeySet
+	Refresh(ctx context.Context, inp *RefreshInput) (*Tokens, *types.Error)
+	SignOut(ctx context.Context, token *AccessToken) *types.Error
+	SignOutAll(ctx context.Context, token *AccessToken) *types.Error
+	// RevokeUser закрывает все сессии юзера, отзывает его живые access токены и убирает его из кеша,
+	// чтобы блокировка или удаление админом действовали сразу
+	RevokeUser(ctx context.Context, userID string) *types.Error
+	// ForgetUser убирает юзера из кеша, следующий запрос перечитает его роли из базы.
+	// Кеш у каждого инстанса свой, остальные инстансы увидят изменения через user_cache_ttl
+	ForgetUser(userID string)
+	// ForgetAllUsers очищает кеш юзеров, например после смены прав роли
+	ForgetAllUsers()
+
+	ForgotPassword(ctx context.Context, inp *ForgotPasswordInput) *types.Error
+	ResetPassword(ctx context.Context, inp *ResetPasswordInput) *types.Error
+	ChangePassword(ctx context.Context, inp *ChangePasswordInput) (*Tokens, *types.Error)
+	ChangeEmail(ctx context.Context, inp *ChangeEmailInput) *types.Error
+	ConfirmChangeEmail(ctx context.Context, inp *ConfirmChangeEmailInput) *types.Error
 
-	SignIn(ctx context.Context, inp *SignInInput) *types.Error
-	ParseToken(ctx context.Context, accessToken string) (*models.User, *types.Error)
+	EnrollTwoFactor(ctx context.Context, inp *EnrollTwoFactorInput) (*TwoFactorEnrollment, *types.Error)
+	ConfirmTwoFactor(ctx context.Context, inp *ConfirmTwoFactorInput) ([]string, *types.Error)
+	DisableTwoFactor(ctx context.Context, inp *DisableTwoFactorInput) *types.Error
+	VerifyTwoFactor(ctx context.Context, inp *VerifyTwoFactorInput) (*Tokens, *types.Error)
 	UpdateProfile(ctx context.Context, inp *UpdateProfileInput) (string, *types.Error)
 
 	GetProfile(ctx context.Context, inp *GetProfileInput) (*models.User, *types.Error)
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"health/models"
+	"health/routes/client/auth"
+	"health/services/jwk"
+	"health/storage"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newMemoryUseCase(t *testing.T) (*UseCase, *storage.Storage) {
+	t.Helper()
+
+	keySet, err := jwk.LoadOrGenerateKeySet(t.TempDir(), "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := storage.NewMemoryStorage()
+	a := NewUseCase(
+		s.Users, s.Sessions, s.Revocations, s.Roles, s.UserRoles, s.Transactions,
+		nil, keySet,
+		15, 24, 60, 60, 30,
+		"http://localhost", LoginModePassword,
+		VerifyCodePolicy{}, TwoFactorPolicy{}, SignInLockPolicy{},
+	)
+
+	return a, s
+}
+
+func createUser(t *testing.T, s *storage.Storage) *models.User {
+	t.Helper()
+
+	user := &models.User{
+		ID:       primitive.NewObjectID().Hex(),
+		Email:    primitive.NewObjectID().Hex() + "@refresh.test",
+		Password: "hash",
+	}
+	if err := s.Users.CreateUser(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+// * Повторный refresh уже ротированным токеном — признак кражи: отзывается вся семья
+// и access токены, выданные по ней, а соседние входы юзера не страдают
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	a, s := newMemoryUseCase(t)
+	user := createUser(t, s)
+
+	first, err := a.CreateSession(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := a.CreateSession(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := a.Refresh(ctx, &auth.RefreshInput{RefreshToken: first.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ParseToken(ctx, second.AccessToken); err != nil {
+		t.Fatalf("fresh access token rejected: %v", err)
+	}
+
+	if _, err := a.Refresh(ctx, &auth.RefreshInput{RefreshToken: first.RefreshToken}); err != &auth.ErrRefreshTokenReused {
+		t.Fatalf("replayed refresh token: got %v, want %v", err, &auth.ErrRefreshTokenReused)
+	}
+
+	if _, err := a.Refresh(ctx, &auth.RefreshInput{RefreshToken: second.RefreshToken}); err != &auth.ErrInvalidRefreshToken {
+		t.Errorf("refresh token of revoked family: got %v, want %v", err, &auth.ErrInvalidRefreshToken)
+	}
+
+	for name, accessToken := range map[string]string{"first": first.AccessToken, "rotated": second.AccessToken} {
+		if _, err := a.ParseToken(ctx, accessToken); err != &auth.ErrAccessTokenRevoked {
+			t.Errorf("%s access token of revoked family: got %v, want %v", name, err, &auth.ErrAccessTokenRevoked)
+		}
+	}
+
+	// * Другой вход того же юзера живет дальше
+	if _, err := a.ParseToken(ctx, other.AccessToken); err != nil {
+		t.Errorf("access token of another family rejected: %v", err)
+	}
+	if _, err := a.Refresh(ctx, &auth.RefreshInput{RefreshToken: other.RefreshToken}); err != nil {
+		t.Errorf("refresh token of another family rejected: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"health/models"
+	"health/routes/client/auth"
+	"health/shared/types"
+	"time"
+)
+
+// SignInLockPolicy — прогрессивная блокировка аккаунта после неверных паролей.
+// После Threshold ошибок аккаунт блокируется на BaseLockout, каждая следующая ошибка удваивает срок до MaxLockout.
+type SignInLockPolicy struct {
+	Threshold   int
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+func (p SignInLockPolicy) lockout(failedAttempts int) time.Duration {
+	lockout := p.BaseLockout
+	for i := p.Threshold; i < failedAttempts; i++ {
+		lockout *= 2
+		if lockout >= p.MaxLockout {
+			return p.MaxLockout
+		}
+	}
+
+	return lockout
+}
+
+// checkLoginPassword сверяет пароль при входе и ведет счетчик неудачных попыток на юзере.
+// В режиме LoginModeCode вход по коду из письма без пароля, но если пароль прислали, он должен быть верным.
+func (a *UseCase) checkLoginPassword(ctx context.Context, user *models.User, password string) *types.Error {
+	if a.loginMode == LoginModeCode && password == "" {
+		return nil
+	}
+
+	now := time.Now()
+	lock := &user.SignInLock
+
+	if now.Before(lock.LockedUntil) {
+		return auth.ErrAccountLocked.WithRetryAfter(lock.LockedUntil.Sub(now))
+	}
+
+	if isEqual, _ := ComparePasswordHash(user.Password, password); !isEqual {
+		// * Только счетчик: юзер прочитан до сверки пароля, полная запись затерла бы параллельные изменения
+		updated, err := a.repo.RecordFailedSignIn(ctx, user.ID, func(failedAttempts int) time.Time {
+			if failedAttempts < a.signInLockPolicy.Threshold {
+				return time.Time{}
+			}
+
+			return now.Add(a.signInLockPolicy.lockout(failedAttempts))
+		})
+		if err != nil {
+			return &auth.ErrCantUpdateUser
+		}
+		*lock = *updated
+
+		if now.Before(lock.LockedUntil) {
+			return auth.ErrAccountLocked.WithRetryAfter(lock.LockedUntil.Sub(now))
+		}
+
+		return &auth.ErrEmailOrPassword
+	}
+
+	if lock.FailedAttempts > 0 || !lock.LockedUntil.IsZero() {
+		if err := a.repo.ResetSignInLock(ctx, user.ID); err != nil {
+			return &auth.ErrCantUpdateUser
+		}
+
+		*lock = models.SignInLock{}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignInLockPolicyLockout(t *testing.T) {
+	policy := SignInLockPolicy{
+		Threshold:   5,
+		BaseLockout: time.Minute,
+		MaxLockout:  15 * time.Minute,
+	}
+
+	tests := []struct {
+		failedAttempts int
+		lockout        time.Duration
+	}{
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{7, 4 * time.Minute},
+		{8, 8 * time.Minute},
+		// * 16 минут уже больше MaxLockout
+		{9, 15 * time.Minute},
+		{10, 15 * time.Minute},
+		{1000, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if lockout := policy.lockout(tt.failedAttempts); lockout != tt.lockout {
+			t.Errorf("lockout(%d) = %s, want %s", tt.failedAttempts, lockout, tt.lockout)
+		}
+	}
+}
+
+func TestSignInLockPolicyLockoutExactMax(t *testing.T) {
+	policy := SignInLockPolicy{
+		Threshold:   3,
+		BaseLockout: time.Minute,
+		MaxLockout:  4 * time.Minute,
+	}
+
+	for failedAttempts, want := range map[int]time.Duration{
+		3: time.Minute,
+		4: 2 * time.Minute,
+		5: 4 * time.Minute,
+		6: 4 * time.Minute,
+	} {
+		if lockout := policy.lockout(failedAttempts); lockout != want {
+			t.Errorf("lockout(%d) = %s, want %s", failedAttempts, lockout, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"health/shared/errs"
+	"health/shared/types"
+)
+
+// errRollback — fn вернула доменную ошибку, транзакцию нужно откатить
+var errRollback = errors.New("rollback")
+
+// inTransaction выполняет fn в транзакции и возвращает ее доменную ошибку как есть
+func (a *UseCase) inTransaction(ctx context.Context, field string, fn func(ctx context.Context) *types.Error) *types.Error {
+	var appErr *types.Error
+
+	err := a.txManager.WithTransaction(ctx, func(ctx context.Context) error {
+		appErr = fn(ctx)
+		if appErr != nil {
+			return errRollback
+		}
+
+		return nil
+	})
+	if appErr != nil {
+		return appErr
+	}
+
+	if err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: field,
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	return nil
+}
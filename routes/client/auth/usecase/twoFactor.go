@@ -0,0 +1,312 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"health/models"
+	"health/routes/client/auth"
+	"health/services/totp"
+	"health/shared/errs"
+	"health/shared/types"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TwoFactorPolicy — настройки TOTP и MFA токена второго шага входа
+type TwoFactorPolicy struct {
+	Issuer       string
+	ChallengeTTL time.Duration
+	// Skew — сколько соседних 30-секундных окон принимать из-за расхождения часов
+	Skew int64
+	// MaxAttempts — сколько неверных кодов можно ввести по одному MFA токену
+	MaxAttempts int
+}
+
+const (
+	recoveryCodesCount = 10
+	recoveryCodeSize   = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// completeSignIn завершает вход: при включенной 2FA вместо токенов выдается MFA токен
+func (a *UseCase) completeSignIn(ctx context.Context, user *models.User) (*auth.SignInResult, *types.Error) {
+	if user.Suspended {
+		return nil, &auth.ErrUserSuspended
+	}
+
+	if user.TwoFactor.Enabled {
+		mfaToken, err := a.issueMFAToken(user)
+		if err != nil {
+			return nil, err
+		}
+
+		return &auth.SignInResult{MFAToken: mfaToken}, nil
+	}
+
+	tokens, err := a.CreateSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.SignInResult{Tokens: tokens}, nil
+}
+
+func (a *UseCase) issueMFAToken(user *models.User) (string, *types.Error) {
+	claims := jwt.StandardClaims{
+		Subject:   user.ID,
+		Audience:  jwt.ClaimStrings{audienceMFA},
+		ID:        primitive.NewObjectID().Hex(),
+		IssuedAt:  jwt.Now(),
+		ExpiresAt: jwt.At(time.Now().Add(a.twoFactorPolicy.ChallengeTTL)),
+	}
+
+	mfaToken, err := a.signToken(claims, tokenTypeMFA)
+	if err != nil {
+		return "", &types.Error{
+			Code:  errs.Internal,
+			Field: "sign-in",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	return mfaToken, nil
+}
+
+func (a *UseCase) EnrollTwoFactor(ctx context.Context, inp *auth.EnrollTwoFactorInput) (*auth.TwoFactorEnrollment, *types.Error) {
+	user, err := a.repo.GetUserById(ctx, inp.ID)
+	if err != nil {
+		return nil, &auth.ErrUserNotFound
+	}
+
+	if user.TwoFactor.Enabled {
+		return nil, &auth.ErrTwoFactorAlreadyEnabled
+	}
+
+	if isEqual, _ := ComparePasswordHash(user.Password, inp.Password); !isEqual {
+		return nil, &auth.ErrEmailOrPassword
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "2fa-enroll",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	// * Повторный enroll просто заменяет неподтвержденный секрет
+	user.TwoFactor = models.TwoFactor{Secret: secret}
+	if err := a.repo.UpdateUser(ctx, user); err != nil {
+		return nil, &auth.ErrCantUpdateUser
+	}
+
+	return &auth.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(a.twoFactorPolicy.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor включает 2FA после первого верного кода и возвращает коды восстановления.
+// Коды показываются один раз, в базе лежат только их хеши.
+func (a *UseCase) ConfirmTwoFactor(ctx context.Context, inp *auth.ConfirmTwoFactorInput) ([]string, *types.Error) {
+	user, err := a.repo.GetUserById(ctx, inp.ID)
+	if err != nil {
+		return nil, &auth.ErrUserNotFound
+	}
+
+	if user.TwoFactor.Enabled {
+		return nil, &auth.ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactor.Secret == "" {
+		return nil, &auth.ErrTwoFactorNotEnrolled
+	}
+
+	if !a.checkTOTP(&user.TwoFactor, inp.Code) {
+		return nil, &auth.ErrInvalidTwoFactorCode
+	}
+
+	recoveryCodes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "2fa-confirm",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	user.TwoFactor.Enabled = true
+	user.TwoFactor.EnabledAt = time.Now()
+	user.TwoFactor.RecoveryCodes = hashes
+
+	if err := a.repo.UpdateUser(ctx, user); err != nil {
+		return nil, &auth.ErrCantUpdateUser
+	}
+
+	return recoveryCodes, nil
+}
+
+func (a *UseCase) DisableTwoFactor(ctx context.Context, inp *auth.DisableTwoFactorInput) *types.Error {
+	user, err := a.repo.GetUserById(ctx, inp.ID)
+	if err != nil {
+		return &auth.ErrUserNotFound
+	}
+
+	if !user.TwoFactor.Enabled {
+		return &auth.ErrTwoFactorNotEnabled
+	}
+
+	if isEqual, _ := ComparePasswordHash(user.Password, inp.Password); !isEqual {
+		return &auth.ErrEmailOrPassword
+	}
+
+	if !a.checkTOTP(&user.TwoFactor, inp.Code) && !useRecoveryCode(&user.TwoFactor, inp.Code) {
+		return &auth.ErrInvalidTwoFactorCode
+	}
+
+	user.TwoFactor = models.TwoFactor{}
+	if err := a.repo.UpdateUser(ctx, user); err != nil {
+		return &auth.ErrCantUpdateUser
+	}
+
+	return nil
+}
+
+// VerifyTwoFactor — второй шаг входа: MFA токен + код из приложения или код восстановления
+func (a *UseCase) VerifyTwoFactor(ctx context.Context, inp *auth.VerifyTwoFactorInput) (*auth.Tokens, *types.Error) {
+	claims := new(jwt.StandardClaims)
+
+	token, err := a.parseSignedToken(inp.MFAToken, claims, tokenTypeMFA, audienceMFA)
+	if err != nil || !token.Valid || claims.ExpiresAt == nil {
+		return nil, &auth.ErrInvalidMFAToken
+	}
+
+	// * MFA токен одноразовый
+	isRevoked, err := a.isTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "2fa-verify",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+	if isRevoked {
+		return nil, &auth.ErrInvalidMFAToken
+	}
+
+	user, err := a.repo.GetUserById(ctx, claims.Subject)
+	if err != nil || !user.TwoFactor.Enabled {
+		return nil, &auth.ErrInvalidMFAToken
+	}
+
+	isValid := false
+	if inp.Code != "" {
+		isValid = a.checkTOTP(&user.TwoFactor, inp.Code)
+	} else {
+		isValid = useRecoveryCode(&user.TwoFactor, inp.RecoveryCode)
+	}
+
+	if !isValid {
+		// * После MaxAttempts неверных кодов токен сгорает и нужно входить заново
+		attempts, _ := a.mfaAttempts.Get(claims.ID)
+		attempts++
+		a.mfaAttempts.Set(claims.ID, attempts)
+
+		if attempts >= a.twoFactorPolicy.MaxAttempts {
+			a.mfaAttempts.Delete(claims.ID)
+			if err := a.revokeToken(ctx, user.ID, claims.ID, claims.ExpiresAt.Time); err != nil {
+				return nil, &types.Error{
+					Code:  errs.Internal,
+					Field: "2fa-verify",
+					Tag:   "auth",
+					Cause: err,
+				}
+			}
+		}
+
+		return nil, &auth.ErrInvalidTwoFactorCode
+	}
+
+	if err := a.revokeToken(ctx, user.ID, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "2fa-verify",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+	a.mfaAttempts.Delete(claims.ID)
+
+	// * Сохраняем LastUsedStep или удаленный код восстановления
+	if err := a.repo.UpdateUser(ctx, user); err != nil {
+		return nil, &auth.ErrCantUpdateUser
+	}
+
+	return a.CreateSession(ctx, user)
+}
+
+// checkTOTP проверяет код и запоминает его шаг, уже использованный код второй раз не пройдет
+func (a *UseCase) checkTOTP(twoFactor *models.TwoFactor, code string) bool {
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now(), a.twoFactorPolicy.Skew)
+	if !ok || step <= twoFactor.LastUsedStep {
+		return false
+	}
+
+	twoFactor.LastUsedStep = step
+
+	return true
+}
+
+// useRecoveryCode проверяет код восстановления и удаляет его, каждый код одноразовый
+func useRecoveryCode(twoFactor *models.TwoFactor, code string) bool {
+	hash := hashRecoveryCode(code)
+
+	for i, stored := range twoFactor.RecoveryCodes {
+		if stored == hash {
+			twoFactor.RecoveryCodes = append(twoFactor.RecoveryCodes[:i], twoFactor.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// generateRecoveryCodes возвращает коды вида xxxxx-xxxxx для юзера и их хеши для базы
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	hashes := make([]string, 0, recoveryCodesCount)
+
+	for i := 0; i < recoveryCodesCount; i++ {
+		buf := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))[:recoveryCodeSize]
+		code := raw[:recoveryCodeSize/2] + "-" + raw[recoveryCodeSize/2:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode — у кодов ~50 бит случайности, поэтому хватает sha256, как у refresh токенов
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"health/models"
+	"health/services/totp"
+)
+
+func TestCheckTOTP(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := &UseCase{twoFactorPolicy: TwoFactorPolicy{Skew: 1}}
+
+	// * checkTOTP берет time.Now сам, не даем тесту перейти границу 30-секундного окна
+	if left := totp.Period - time.Duration(time.Now().Unix()%int64(totp.Period.Seconds()))*time.Second; left < 2*time.Second {
+		time.Sleep(left)
+	}
+	current := totp.Step(time.Now())
+
+	code := func(step int64) string {
+		value, err := totp.Code(secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return value
+	}
+
+	t.Run("current code", func(t *testing.T) {
+		twoFactor := &models.TwoFactor{Secret: secret}
+		if !a.checkTOTP(twoFactor, code(current)) {
+			t.Fatal("current code rejected")
+		}
+		if twoFactor.LastUsedStep != current {
+			t.Errorf("LastUsedStep = %d, want %d", twoFactor.LastUsedStep, current)
+		}
+
+		// * Тот же код второй раз не проходит
+		if a.checkTOTP(twoFactor, code(current)) {
+			t.Error("replayed code accepted")
+		}
+	})
+
+	t.Run("step not after last used", func(t *testing.T) {
+		twoFactor := &models.TwoFactor{Secret: secret, LastUsedStep: current}
+		if a.checkTOTP(twoFactor, code(current-1)) {
+			t.Error("code older than last used step accepted")
+		}
+
+		if !a.checkTOTP(twoFactor, code(current+1)) {
+			t.Error("code newer than last used step rejected")
+		}
+		if twoFactor.LastUsedStep != current+1 {
+			t.Errorf("LastUsedStep = %d, want %d", twoFactor.LastUsedStep, current+1)
+		}
+	})
+
+	t.Run("skew window", func(t *testing.T) {
+		tests := []struct {
+			shift int64
+			ok    bool
+		}{
+			{-2, false},
+			{-1, true},
+			{1, true},
+			{2, false},
+		}
+
+		for _, tt := range tests {
+			twoFactor := &models.TwoFactor{Secret: secret}
+			if ok := a.checkTOTP(twoFactor, code(current+tt.shift)); ok != tt.ok {
+				t.Errorf("code %+d steps away: ok = %v, want %v", tt.shift, ok, tt.ok)
+			}
+		}
+	})
+
+	t.Run("wrong code", func(t *testing.T) {
+		twoFactor := &models.TwoFactor{Secret: secret}
+		if a.checkTOTP(twoFactor, "not-a-code") {
+			t.Error("wrong code accepted")
+		}
+		if twoFactor.LastUsedStep != 0 {
+			t.Errorf("LastUsedStep changed on wrong code: %d", twoFactor.LastUsedStep)
+		}
+	})
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodesCount || len(hashes) != recoveryCodesCount {
+		t.Fatalf("generated %d codes and %d hashes, want %d", len(codes), len(hashes), recoveryCodesCount)
+	}
+
+	twoFactor := &models.TwoFactor{RecoveryCodes: hashes}
+
+	if !useRecoveryCode(twoFactor, codes[3]) {
+		t.Fatal("recovery code rejected")
+	}
+	if len(twoFactor.RecoveryCodes) != recoveryCodesCount-1 {
+		t.Errorf("%d recovery codes left, want %d", len(twoFactor.RecoveryCodes), recoveryCodesCount-1)
+	}
+
+	// * Код одноразовый
+	if useRecoveryCode(twoFactor, codes[3]) {
+		t.Error("recovery code accepted twice")
+	}
+
+	// * Регистр, дефис и пробелы по краям не важны
+	if !useRecoveryCode(twoFactor, "  "+strings.ToUpper(strings.ReplaceAll(codes[5], "-", ""))+" ") {
+		t.Error("recovery code without dash in upper case rejected")
+	}
+
+	if useRecoveryCode(twoFactor, "aaaaa-aaaaa") {
+		t.Error("unknown recovery code accepted")
+	}
+	if len(twoFactor.RecoveryCodes) != recoveryCodesCount-2 {
+		t.Errorf("%d recovery codes left, want %d", len(twoFactor.RecoveryCodes), recoveryCodesCount-2)
+	}
+}
@@ -2,18 +2,26 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"health/models"
 	"health/services/email"
 	service_email "health/services/email"
-	"math/rand"
-	"strconv"
+	"health/services/i18n"
+	"health/services/jwk"
+	"health/services/transaction"
+	"net/url"
 	"time"
 
 	"health/routes/client/auth"
 	"health/routes/client/role"
 	"health/routes/client/userRole"
+	"health/shared/cache"
+	"health/shared/errs"
 	"health/shared/types"
 	"health/shared/utils"
 
@@ -22,36 +30,113 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// AuthClaims — в токене только ссылки, сам юзер резолвится из базы на каждый запрос
 type AuthClaims struct {
 	jwt.StandardClaims
-	User *models.User `json:"user"`
+	SessionID    string `json:"sid,omitempty"`
+	RolesVersion int    `json:"rv"`
 }
 
+// ResetPasswordClaims — одноразовая ссылка сброса пароля.
+// PasswordFingerprint привязывает ее к текущему паролю: после смены пароля ссылка не работает.
+type ResetPasswordClaims struct {
+	jwt.StandardClaims
+	PasswordFingerprint string `json:"pwh"`
+}
+
+// * Служебные токены подписаны теми же ключами, что и access, и проверяются тем же JWKS.
+// Поэтому у каждого назначения свой заголовок typ и свой aud: сервис, который проверяет
+// access токены по JWKS, должен требовать typ at+jwt и aud health-api.
+const (
+	tokenTypeAccess        = "at+jwt"
+	tokenTypeMFA           = "mfa+jwt"
+	tokenTypePasswordReset = "reset+jwt"
+
+	audienceAccess        = "health-api"
+	audienceMFA           = "health-mfa"
+	audiencePasswordReset = "health-password-reset"
+)
+
+var errUnexpectedTokenType = errors.New("unexpected token type or audience")
+
+// LoginMode — как юзер входит в систему
+type LoginMode string
+
+const (
+	// LoginModePassword — SignIn по email и паролю сразу выдает токены
+	LoginModePassword LoginMode = "password"
+	// LoginModeCode — вход без пароля, по коду из письма через send/check-verify-code
+	LoginModeCode LoginMode = "code"
+)
+
 type UseCase struct {
-	repo           auth.Repository
-	roleRepo       role.Repository
-	userRoleRepo   userRole.Repository
-	mailer         *email.Mailer
-	signingKey     []byte
-	expireDuration time.Duration
+	repo                  auth.Repository
+	sessionRepo           auth.SessionRepository
+	revocationRepo        auth.RevocationRepository
+	roleRepo              role.Repository
+	userRoleRepo          userRole.Repository
+	txManager             transaction.Manager
+	mailer                *email.Mailer
+	keySet                *jwk.KeySet
+	expireDuration        time.Duration
+	refreshExpireDuration time.Duration
+	resetExpireDuration   time.Duration
+	clientURL             string
+	loginMode             LoginMode
+	verifyCodePolicy      VerifyCodePolicy
+	twoFactorPolicy       TwoFactorPolicy
+	signInLockPolicy      SignInLockPolicy
+
+	// * jti -> отозван ли токен, чтобы middleware не ходил в базу на каждый запрос
+	revocationCache *cache.Cache[string, bool]
+	// * id юзера -> юзер с ролями
+	userCache *cache.Cache[string, *models.User]
+	// * jti MFA токена -> число неверных кодов
+	mfaAttempts *cache.Cache[string, int]
 }
 
 func NewUseCase(
 	repo auth.Repository,
+	sessionRepo auth.SessionRepository,
+	revocationRepo auth.RevocationRepository,
 	roleRepo role.Repository,
 	userRoleRepo userRole.Repository,
+	txManager transaction.Manager,
 
 	mailer *service_email.Mailer,
-	signingKey []byte,
-	tokenTTLHours time.Duration) *UseCase {
+	keySet *jwk.KeySet,
+	accessTokenTTLMinutes time.Duration,
+	refreshTokenTTLHours time.Duration,
+	revocationCacheTTLSeconds time.Duration,
+	userCacheTTLSeconds time.Duration,
+	resetPasswordTTLMinutes time.Duration,
+	clientURL string,
+	loginMode LoginMode,
+	verifyCodePolicy VerifyCodePolicy,
+	twoFactorPolicy TwoFactorPolicy,
+	signInLockPolicy SignInLockPolicy) *UseCase {
 	return &UseCase{
-		repo:         repo,
-		roleRepo:     roleRepo,
-		userRoleRepo: userRoleRepo,
-
-		mailer:         mailer,
-		signingKey:     signingKey,
-		expireDuration: time.Hour * tokenTTLHours,
+		repo:           repo,
+		sessionRepo:    sessionRepo,
+		revocationRepo: revocationRepo,
+		roleRepo:       roleRepo,
+		userRoleRepo:   userRoleRepo,
+		txManager:      txManager,
+
+		mailer:                mailer,
+		keySet:                keySet,
+		expireDuration:        time.Minute * accessTokenTTLMinutes,
+		refreshExpireDuration: time.Hour * refreshTokenTTLHours,
+		resetExpireDuration:   time.Minute * resetPasswordTTLMinutes,
+		clientURL:             clientURL,
+		loginMode:             loginMode,
+		verifyCodePolicy:      verifyCodePolicy,
+		twoFactorPolicy:       twoFactorPolicy,
+		signInLockPolicy:      signInLockPolicy,
+
+		revocationCache: cache.New[string, bool](time.Second * revocationCacheTTLSeconds),
+		userCache:       cache.New[string, *models.User](time.Second * userCacheTTLSeconds),
+		mfaAttempts:     cache.New[string, int](twoFactorPolicy.ChallengeTTL),
 	}
 }
 
@@ -76,6 +161,9 @@ func ComparePasswordHash(password1, password2 string) (bool, error) {
 func (a *UseCase) MakeClearUser(ctx context.Context, user *models.User) (*models.User, error) {
 	utils.RemoveKeyFromStruct(user, "Password")
 	utils.RemoveKeyFromStruct(user, "PasswordConfirm")
+	utils.RemoveKeyFromStruct(user, "VerifyCode")
+	utils.RemoveKeyFromStruct(user, "EmailChange")
+	utils.RemoveKeyFromStruct(user, "TwoFactor")
 
 	userRoles, err := a.userRoleRepo.GetUserRoleByIDs(ctx, user.UserRoleIDs)
 	if err != nil {
@@ -92,9 +180,10 @@ func (a *UseCase) MakeClearUser(ctx context.Context, user *models.User) (*models
 		for _, role := range roles {
 			if userRole.RoleID == role.ID {
 				resUserRoles = append(resUserRoles, &models.UserRoleWithRole{
-					Name:      role.Name,
-					Status:    userRole.Status,
-					IsDefault: role.IsDefault,
+					Name:        role.Name,
+					Status:      userRole.Status,
+					IsDefault:   role.IsDefault,
+					Permissions: role.Permissions,
 				})
 
 				break
@@ -108,78 +197,91 @@ func (a *UseCase) MakeClearUser(ctx context.Context, user *models.User) (*models
 }
 
 func (a *UseCase) SignUp(ctx context.Context, inp *auth.SignUpInput) *types.Error {
-	user, err := a.repo.GetUserByEmail(ctx, inp.Email)
-	if err == nil {
-		return &auth.ErrUserIsExist
-	}
-
 	hashPassword, err := HashPassword(inp.Password)
 	if err != nil {
 		return &types.Error{
-			Message: err.Error(),
-			Field:   "sign-up",
-			Tag:     "auth",
+			Code:  errs.Internal,
+			Field: "sign-up",
+			Tag:   "auth",
+			Cause: err,
 		}
 	}
 
 	hashPasswordConfirm, err := HashPassword(inp.PasswordConfirm)
 	if err != nil {
 		return &types.Error{
-			Message: err.Error(),
-			Field:   "sign-up",
-			Tag:     "auth",
+			Code:  errs.Internal,
+			Field: "sign-up",
+			Tag:   "auth",
+			Cause: err,
 		}
 	}
 
-	// Находим роль обычного юзера
-	role, err := a.roleRepo.GetRoleByName(ctx, string(models.RoleNameUser))
-	if err != nil {
-		return &types.Error{
-			Message: err.Error(),
-			Field:   "sign-up",
-			Tag:     "auth",
+	// * Юзер и его базовая роль создаются вместе, иначе при сбое остается роль без юзера
+	return a.inTransaction(ctx, "sign-up", func(ctx context.Context) *types.Error {
+		if _, err := a.repo.GetUserByEmail(ctx, inp.Email); err == nil {
+			return &auth.ErrUserIsExist
 		}
-	}
 
-	userRoleId := primitive.NewObjectID()
-	userId := primitive.NewObjectID()
+		// Находим роль обычного юзера
+		role, err := a.roleRepo.GetRoleByName(ctx, string(models.RoleNameUser))
+		if err != nil {
+			return &types.Error{
+				Code:  errs.Internal,
+				Field: "sign-up",
+				Tag:   "auth",
+				Cause: err,
+			}
+		}
 
-	// Создаем юзер.роль
-	userRole := models.UserRole{
-		ID:     userRoleId.Hex(),
-		UserID: userId.Hex(),
-		RoleID: role.ID,
-		Status: models.UserRoleStatusApproved,
-	}
-	err = a.userRoleRepo.CreateUserRole(ctx, &userRole)
-	if err != nil {
-		return &types.Error{
-			Message: err.Error(),
-			Field:   "sign-up",
-			Tag:     "auth",
+		userRoleId := primitive.NewObjectID()
+		userId := primitive.NewObjectID()
+
+		// Создаем юзер.роль
+		userRole := models.UserRole{
+			ID:     userRoleId.Hex(),
+			UserID: userId.Hex(),
+			RoleID: role.ID,
+			Status: models.UserRoleStatusApproved,
+		}
+		err = a.userRoleRepo.CreateUserRole(ctx, &userRole)
+		if err != nil {
+			return &types.Error{
+				Code:  errs.Internal,
+				Field: "sign-up",
+				Tag:   "auth",
+				Cause: err,
+			}
 		}
-	}
 
-	// подумать
-	user = &models.User{
-		ID:                   userId.Hex(),
-		UserRoleIDs:          []string{userRoleId.Hex()},
-		Email:                inp.Email,
-		Password:             hashPassword,
-		PasswordConfirm:      hashPasswordConfirm,
-		Verified:             false,
-		FinishedRegistration: false,
-	}
+		// подумать
+		user := &models.User{
+			ID:                   userId.Hex(),
+			UserRoleIDs:          []string{userRoleId.Hex()},
+			Email:                inp.Email,
+			Password:             hashPassword,
+			PasswordConfirm:      hashPasswordConfirm,
+			Verified:             false,
+			FinishedRegistration: false,
+			Locale:               inp.Locale,
+		}
 
-	if err := a.repo.CreateUser(ctx, user); err != nil {
-		return &types.Error{
-			Message: err.Error(),
-			Field:   "sign-up",
-			Tag:     "auth",
+		// * Параллельная регистрация с тем же email упрется в уникальный индекс
+		err = a.repo.CreateUser(ctx, user)
+		if errors.Is(err, auth.ErrEmailIsTaken) {
+			return &auth.ErrUserIsExist
+		}
+		if err != nil {
+			return &types.Error{
+				Code:  errs.Internal,
+				Field: "sign-up",
+				Tag:   "auth",
+				Cause: err,
+			}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
 type EmailContent struct {
@@ -192,38 +294,33 @@ func (a *UseCase) SendVerifyCode(ctx context.Context, inp *auth.SendVerifyCodeIn
 		return &auth.ErrUserNotFound
 	}
 
-	isEqual, err := ComparePasswordHash(user.Password, inp.Password)
-	if err != nil {
-		return &types.Error{
-			Message: err.Error(),
-			Field:   "send-verify-code",
-			Tag:     "auth",
-		}
-	}
-	if !isEqual {
-		return &auth.ErrEmailOrPassword
+	if err := a.checkLoginPassword(ctx, user, inp.Password); err != nil {
+		return err
 	}
 
-	minVal := 100000
-	maxVal := 999999
-	randomCode := rand.Intn(maxVal-minVal+1) + minVal
+	verifyCode, codeErr := a.issueVerifyCode(&user.VerifyCode)
+	if codeErr != nil {
+		return codeErr
+	}
 
-	user.VerifyCode = strconv.Itoa(randomCode)
 	if err := a.repo.UpdateUser(ctx, user); err != nil {
 		return &types.Error{
-			Message: err.Error(),
-			Field:   "send-verify-code",
-			Tag:     "auth",
+			Code:  errs.Internal,
+			Field: "send-verify-code",
+			Tag:   "auth",
+			Cause: err,
 		}
 	}
 
 	// Отправляем письмо
+	locale := i18n.Preferred(ctx, user.Locale)
 	emailMessage := service_email.Message{
-		Subject:      "Your service: verify code",
+		Subject:      i18n.T(locale, "email.verify_code.subject"),
 		To:           []string{inp.Email},
 		TemplateName: "VerifyCode",
+		Locale:       locale,
 		Content: EmailContent{
-			VerifyCode: user.VerifyCode,
+			VerifyCode: verifyCode,
 		},
 	}
 	a.mailer.Send(&emailMessage)
@@ -231,261 +328,981 @@ func (a *UseCase) SendVerifyCode(ctx context.Context, inp *auth.SendVerifyCodeIn
 	return nil
 }
 
-func (a *UseCase) CheckVerifyCode(ctx context.Context, inp *auth.CheckVerifyCodeInput) (string, *types.Error) {
+func (a *UseCase) CheckVerifyCode(ctx context.Context, inp *auth.CheckVerifyCodeInput) (*auth.SignInResult, *types.Error) {
 	user, err := a.repo.GetUserByEmail(ctx, inp.Email)
 	if err != nil {
-		return "", &auth.ErrUserNotFound
+		return nil, &auth.ErrUserNotFound
 	}
 
-	isEqual, err := ComparePasswordHash(user.Password, inp.Password)
-	if err != nil {
-		return "", &types.Error{
-			Message: err.Error(),
-			Field:   "check-verify-code",
-			Tag:     "auth",
-		}
-	}
-	if !isEqual {
-		return "", &auth.ErrEmailOrPassword
+	if err := a.checkLoginPassword(ctx, user, inp.Password); err != nil {
+		return nil, err
 	}
 
-	if user.VerifyCode != inp.VerifyCode {
-		return "", &auth.ErrVerifyCodeNotMatch
+	if codeErr := a.checkVerifyCode(ctx, user.ID, auth.VerifyCodeEmail, &user.VerifyCode, inp.VerifyCode); codeErr != nil {
+		return nil, codeErr
 	}
 
 	user.Verified = true
-	user.VerifyCode = ""
 
 	if err := a.repo.UpdateUser(ctx, user); err != nil {
-		return "", &types.Error{
-			Message: err.Error(),
-			Field:   "sign-up",
-			Tag:     "auth",
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "sign-up",
+			Tag:   "auth",
+			Cause: err,
 		}
 	}
 
-	return a.GetToken(ctx, user)
+	return a.completeSignIn(ctx, user)
 }
 
-func (a *UseCase) SignIn(ctx context.Context, inp *auth.SignInInput) *types.Error {
-	user, err := a.repo.GetUserByEmail(ctx, inp.Email)
-	if err != nil {
-		return &auth.ErrUserNotFound
+func (a *UseCase) SignIn(ctx context.Context, inp *auth.SignInInput) (*auth.SignInResult, *types.Error) {
+	if a.loginMode == LoginModeCode {
+		return nil, &auth.ErrPasswordSignInDisabled
 	}
 
-	isEqual, err := ComparePasswordHash(user.Password, inp.Password)
+	user, err := a.repo.GetUserByEmail(ctx, inp.Email)
 	if err != nil {
-		return &types.Error{
-			Message: err.Error(),
-			Field:   "send-verify-code",
-			Tag:     "auth",
-		}
+		return nil, &auth.ErrUserNotFound
 	}
-	if !isEqual {
-		return &auth.ErrEmailOrPassword
+
+	if err := a.checkLoginPassword(ctx, user, inp.Password); err != nil {
+		return nil, err
 	}
 
 	// Если юзер не verified, то он не может зайти
 	if !user.Verified {
-		return &auth.ErrUserIsUnauthorized
+		return nil, &auth.ErrUserIsUnauthorized
 	}
 
-	return nil
+	return a.completeSignIn(ctx, user)
 }
 
-func (a *UseCase) GetToken(ctx context.Context, user *models.User) (string, *types.Error) {
-	user, err := a.MakeClearUser(ctx, user)
-	if err != nil {
-		return "", &types.Error{
-			Message: err.Error(),
-			Field:   "create-token",
-			Tag:     "auth",
-		}
-	}
+// GetToken подписывает access token для сессии и записывает в нее его jti
+func (a *UseCase) GetToken(ctx context.Context, user *models.User, session *models.Session) (string, *types.Error) {
+	session.AccessTokenID = primitive.NewObjectID().Hex()
 
 	claims := AuthClaims{
-		User: user,
+		SessionID:    session.FamilyID,
+		RolesVersion: user.RolesVersion,
 		StandardClaims: jwt.StandardClaims{
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{audienceAccess},
+			ID:        session.AccessTokenID,
+			IssuedAt:  jwt.Now(),
 			ExpiresAt: jwt.At(time.Now().Add(a.expireDuration)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	completeSignedToken, err := token.SignedString(a.signingKey)
+	completeSignedToken, err := a.signToken(claims, tokenTypeAccess)
 
 	if err != nil {
 		return "", &types.Error{
-			Message: err.Error(),
-			Field:   "create-token",
-			Tag:     "auth",
+			Code:  errs.Internal,
+			Field: "create-token",
+			Tag:   "auth",
+			Cause: err,
 		}
 	}
 
 	return completeSignedToken, nil
 }
 
-func (a *UseCase) ParseToken(ctx context.Context, accessToken string) (*models.User, *types.Error) {
-	token, err := jwt.ParseWithClaims(accessToken, &AuthClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+// CreateSession открывает новую семью refresh токенов и выдает пару access/refresh.
+func (a *UseCase) CreateSession(ctx context.Context, user *models.User) (*auth.Tokens, *types.Error) {
+	return a.issueTokens(ctx, user, primitive.NewObjectID().Hex())
+}
+
+// Refresh меняет refresh token на новую пару. Старый токен после этого использовать нельзя:
+// повторное предъявление считается кражей и отзывает всю семью сессий.
+func (a *UseCase) Refresh(ctx context.Context, inp *auth.RefreshInput) (*auth.Tokens, *types.Error) {
+	session, err := a.sessionRepo.GetSessionByTokenHash(ctx, hashRefreshToken(inp.RefreshToken))
+	if err != nil {
+		return nil, &auth.ErrInvalidRefreshToken
+	}
+
+	if session.Revoked {
+		return nil, &auth.ErrInvalidRefreshToken
+	}
+
+	if session.Rotated {
+		return nil, a.revokeReusedSession(ctx, session)
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return nil, &auth.ErrRefreshTokenExpired
+	}
+
+	user, err := a.repo.GetUserById(ctx, session.UserID)
+	if err != nil {
+		return nil, &auth.ErrUserNotFound
+	}
+
+	isRotated, err := a.sessionRepo.RotateSession(ctx, session.ID)
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "refresh",
+			Tag:   "auth",
+			Cause: err,
 		}
-		return a.signingKey, nil
-	})
+	}
+	// * Кто-то успел использовать этот же токен параллельно
+	if !isRotated {
+		return nil, a.revokeReusedSession(ctx, session)
+	}
+
+	return a.issueTokens(ctx, user, session.FamilyID)
+}
+
+// revokeReusedSession — refresh токен предъявили повторно: отзываем всю семью вместе с ее access токенами,
+// иначе украденный access токен жил бы до конца своего TTL
+func (a *UseCase) revokeReusedSession(ctx context.Context, session *models.Session) *types.Error {
+	if err := a.revokeSessionFamily(ctx, session.UserID, session.FamilyID); err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "refresh",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	return &auth.ErrRefreshTokenReused
+}
+
+func (a *UseCase) issueTokens(ctx context.Context, user *models.User, familyID string) (*auth.Tokens, *types.Error) {
+	if user.Suspended {
+		return nil, &auth.ErrUserSuspended
+	}
 
+	refreshToken, err := generateRefreshToken()
 	if err != nil {
 		return nil, &types.Error{
-			Message: err.Error(),
-			Field:   "parse-token",
-			Tag:     "auth",
+			Code:  errs.Internal,
+			Field: "create-token",
+			Tag:   "auth",
+			Cause: err,
 		}
 	}
 
-	if claims, ok := token.Claims.(*AuthClaims); ok && token.Valid {
-		return claims.User, nil
+	session := models.Session{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt: time.Now().Add(a.refreshExpireDuration),
+	}
+
+	accessToken, tokenErr := a.GetToken(ctx, user, &session)
+	if tokenErr != nil {
+		return nil, tokenErr
+	}
+
+	if err := a.sessionRepo.CreateSession(ctx, &session); err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "create-token",
+			Tag:   "auth",
+			Cause: err,
+		}
 	}
 
-	return nil, &auth.ErrInvalidAccessToken
+	return &auth.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
 }
 
-func (a *UseCase) GetProfile(ctx context.Context, inp *auth.GetProfileInput) (*models.User, *types.Error) {
-	user, err := a.repo.GetUserById(ctx, inp.ID)
+// generateRefreshToken — непрозрачный случайный токен, в базе хранится только его хеш
+func generateRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
 
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (a *UseCase) ParseToken(ctx context.Context, accessToken string) (*auth.AccessToken, *types.Error) {
+	claims := new(AuthClaims)
+	token, err := a.parseSignedToken(accessToken, claims, tokenTypeAccess, audienceAccess)
+
+	// * Мусор, чужая подпись, неизвестный kid, токен другого назначения и истекший токен — ошибка клиента, а не сервера
 	if err != nil {
-		return nil, &auth.ErrUserNotFound
+		if vErr, ok := err.(*jwt.ValidationError); ok && vErr.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, &auth.ErrAccessTokenExpired
+		}
+
+		return nil, &auth.ErrInvalidAccessToken
+	}
+
+	if !token.Valid || claims.ExpiresAt == nil {
+		return nil, &auth.ErrInvalidAccessToken
+	}
+
+	isRevoked, err := a.isTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "parse-token",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+	if isRevoked {
+		return nil, &auth.ErrAccessTokenRevoked
+	}
+
+	return &auth.AccessToken{
+		ID:           claims.ID,
+		SessionID:    claims.SessionID,
+		UserID:       claims.Subject,
+		RolesVersion: claims.RolesVersion,
+		ExpiresAt:    claims.ExpiresAt.Time,
+	}, nil
+}
+
+// GetUserByToken возвращает актуального юзера с ролями.
+// Кешированная запись годится, только если она не старше ролей, с которыми выдан токен.
+func (a *UseCase) GetUserByToken(ctx context.Context, token *auth.AccessToken) (*models.User, *types.Error) {
+	if user, ok := a.userCache.Get(token.UserID); ok && user.RolesVersion >= token.RolesVersion {
+		if user.Suspended {
+			return nil, &auth.ErrUserSuspended
+		}
+
+		return user, nil
+	}
+
+	// * Токен валиден, но юзера уже нет — для авторизованного роута это 401, а не 404
+	user, err := a.repo.GetUserById(ctx, token.UserID)
+	if errors.Is(err, errs.ErrNotFound) {
+		return nil, &auth.ErrInvalidAccessToken
+	}
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "parse-token",
+			Tag:   "auth",
+			Cause: err,
+		}
 	}
 
 	user, err = a.MakeClearUser(ctx, user)
 	if err != nil {
 		return nil, &types.Error{
-			Message: err.Error(),
-			Field:   "get-profile",
-			Tag:     "auth",
+			Code:  errs.Internal,
+			Field: "parse-token",
+			Tag:   "auth",
+			Cause: err,
 		}
 	}
+	a.userCache.Set(user.ID, user)
+
+	if user.Suspended {
+		return nil, &auth.ErrUserSuspended
+	}
 
 	return user, nil
 }
 
-func (a *UseCase) UpdateProfile(ctx context.Context, inp *auth.UpdateProfileInput) (string, *types.Error) {
-	// * Получаем юзера
-	user, err := a.repo.GetUserById(ctx, inp.ID)
+// signToken подписывает claims активным ключом, tokenType уходит в заголовок typ
+func (a *UseCase) signToken(claims jwt.Claims, tokenType string) (string, error) {
+	key := a.keySet.SigningKey()
+
+	token := jwt.NewWithClaims(key.SigningMethod(), claims)
+	token.Header["kid"] = key.ID
+	token.Header["typ"] = tokenType
+
+	return token.SignedString(key.Private)
+}
+
+type audienceClaims interface {
+	jwt.Claims
+	VerifyAudience(cmp string, req bool) bool
+}
+
+// parseSignedToken проверяет подпись и срок, а затем typ и aud:
+// токен другого назначения, подписанный теми же ключами, не пройдет
+func (a *UseCase) parseSignedToken(raw string, claims audienceClaims, tokenType string, audience string) (*jwt.Token, error) {
+	token, err := jwt.ParseWithClaims(raw, claims, a.verificationKey)
 	if err != nil {
-		return "", &auth.ErrUserNotFound
+		return nil, err
+	}
+
+	if typ, _ := token.Header["typ"].(string); typ != tokenType || !claims.VerifyAudience(audience, true) {
+		return nil, errUnexpectedTokenType
 	}
 
-	// * Закончили регистрацию до конца
-	user.FinishedRegistration = true
+	return token, nil
+}
+
+// verificationKey выбирает ключ проверки по kid из заголовка токена
+func (a *UseCase) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := a.keySet.VerificationKey(kid)
+	if !ok {
+		return nil, fmt.Errorf("Unknown signing key: %v", token.Header["kid"])
+	}
 
-	user.IIN = inp.IIN
-	user.Name = inp.Name
-	user.Surname = inp.Surname
-	user.Birthday = inp.Birthday
-	user.Gender = inp.Gender
-	user.Address = models.Address(inp.Address)
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.Public, nil
+}
 
-	// * Находим роль юзера
-	roleUser, err := a.roleRepo.GetRoleByName(ctx, string(models.RoleNameUser))
+func (a *UseCase) JWKS() *jwk.JSONWebKeySet {
+	return a.keySet.JWKS()
+}
+
+func (a *UseCase) isTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	if isRevoked, ok := a.revocationCache.Get(tokenID); ok {
+		return isRevoked, nil
+	}
+
+	isRevoked, err := a.revocationRepo.IsTokenRevoked(ctx, tokenID)
 	if err != nil {
-		return "", &types.Error{
-			Message: err.Error(),
-			Field:   "update-profile",
-			Tag:     "auth",
+		return false, err
+	}
+	a.revocationCache.Set(tokenID, isRevoked)
+
+	return isRevoked, nil
+}
+
+func (a *UseCase) revokeToken(ctx context.Context, userID string, tokenID string, expiresAt time.Time) error {
+	// * Токен уже истек сам, отзывать нечего
+	if time.Now().After(expiresAt) {
+		return nil
+	}
+
+	err := a.revocationRepo.RevokeToken(ctx, &models.RevokedToken{
+		TokenID:   tokenID,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	a.revocationCache.SetWithTTL(tokenID, true, time.Until(expiresAt))
+
+	return nil
+}
+
+// SignOut отзывает текущий access token и всю семью refresh токенов этого входа
+func (a *UseCase) SignOut(ctx context.Context, token *auth.AccessToken) *types.Error {
+	if err := a.revokeToken(ctx, token.UserID, token.ID, token.ExpiresAt); err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "sign-out",
+			Tag:   "auth",
+			Cause: err,
 		}
 	}
 
-	var newUserRoleIDs []string
-	// * Все текущие юзер-роли у юзера
-	currUserRoles, err := a.userRoleRepo.GetUserRoleByIDs(ctx, user.UserRoleIDs)
+	if token.SessionID != "" {
+		if err := a.sessionRepo.RevokeSessionFamily(ctx, token.SessionID); err != nil {
+			return &types.Error{
+				Code:  errs.Internal,
+				Field: "sign-out",
+				Tag:   "auth",
+				Cause: err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// SignOutAll завершает все сессии юзера на всех устройствах
+func (a *UseCase) SignOutAll(ctx context.Context, token *auth.AccessToken) *types.Error {
+	if err := a.revokeUserSessions(ctx, token.UserID); err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "sign-out-all",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	if err := a.revokeToken(ctx, token.UserID, token.ID, token.ExpiresAt); err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "sign-out-all",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	return nil
+}
+
+func (a *UseCase) RevokeUser(ctx context.Context, userID string) *types.Error {
+	if err := a.revokeUserSessions(ctx, userID); err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "revoke-user",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+	a.userCache.Delete(userID)
+
+	return nil
+}
+
+func (a *UseCase) ForgetUser(userID string) {
+	a.userCache.Delete(userID)
+}
+
+func (a *UseCase) ForgetAllUsers() {
+	a.userCache.Clear()
+}
+
+func (a *UseCase) revokeUserSessions(ctx context.Context, userID string) error {
+	sessions, err := a.sessionRepo.GetSessionsByUserID(ctx, userID)
 	if err != nil {
-		return "", &types.Error{
-			Message: err.Error(),
-			Field:   "user-role-ids",
-			Tag:     "auth",
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := a.revokeSessionAccessToken(ctx, session); err != nil {
+			return err
+		}
+	}
+
+	return a.sessionRepo.RevokeUserSessions(ctx, userID)
+}
+
+func (a *UseCase) revokeSessionFamily(ctx context.Context, userID string, familyID string) error {
+	sessions, err := a.sessionRepo.GetSessionsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.FamilyID != familyID {
+			continue
 		}
+
+		if err := a.revokeSessionAccessToken(ctx, session); err != nil {
+			return err
+		}
+	}
+
+	return a.sessionRepo.RevokeSessionFamily(ctx, familyID)
+}
+
+// revokeSessionAccessToken — access токен сессии еще может быть жив, отзываем его по jti.
+// Выдан он не позже UpdatedAt, так что истекает не позже UpdatedAt + TTL.
+func (a *UseCase) revokeSessionAccessToken(ctx context.Context, session *models.Session) error {
+	if session.AccessTokenID == "" {
+		return nil
+	}
+
+	return a.revokeToken(ctx, session.UserID, session.AccessTokenID, session.UpdatedAt.Add(a.expireDuration))
+}
+
+type ResetPasswordEmailContent struct {
+	Link       string
+	TTLMinutes int
+}
+
+// ForgotPassword отправляет ссылку сброса пароля. Ответ для любого email одинаковый, а письмо
+// уходит в фоне, чтобы время ответа не зависело от SMTP. От перебора email это само по себе
+// не защищает: зарегистрирован ли адрес, видно и по sign-up.
+func (a *UseCase) ForgotPassword(ctx context.Context, inp *auth.ForgotPasswordInput) *types.Error {
+	user, err := a.repo.GetUserByEmail(ctx, inp.Email)
+	if err != nil {
+		return nil
 	}
 
-	for _, currUserRole := range currUserRoles {
-		if currUserRole.RoleID == roleUser.ID {
-			newUserRoleIDs = []string{currUserRole.ID}
+	claims := ResetPasswordClaims{
+		PasswordFingerprint: passwordFingerprint(user.Password),
+		StandardClaims: jwt.StandardClaims{
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{audiencePasswordReset},
+			ID:        primitive.NewObjectID().Hex(),
+			IssuedAt:  jwt.Now(),
+			ExpiresAt: jwt.At(time.Now().Add(a.resetExpireDuration)),
+		},
+	}
+
+	resetToken, err := a.signToken(claims, tokenTypePasswordReset)
+	if err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "forgot-password",
+			Tag:   "auth",
+			Cause: err,
 		}
 	}
 
-	// * Находим все роли из запроса
-	inpRoles, err := a.roleRepo.GetRoleByIDs(ctx, inp.RoleIDs)
+	// Отправляем письмо
+	locale := i18n.Preferred(ctx, user.Locale)
+	emailMessage := service_email.Message{
+		Subject:      i18n.T(locale, "email.reset_password.subject"),
+		To:           []string{user.Email},
+		TemplateName: "ResetPassword",
+		Locale:       locale,
+		Content: ResetPasswordEmailContent{
+			Link:       fmt.Sprintf("%s/reset-password?token=%s", a.clientURL, url.QueryEscape(resetToken)),
+			TTLMinutes: int(a.resetExpireDuration.Minutes()),
+		},
+	}
+	go a.mailer.Send(&emailMessage)
+
+	return nil
+}
+
+func (a *UseCase) ResetPassword(ctx context.Context, inp *auth.ResetPasswordInput) *types.Error {
+	claims := new(ResetPasswordClaims)
+
+	token, err := a.parseSignedToken(inp.Token, claims, tokenTypePasswordReset, audiencePasswordReset)
+	if err != nil || !token.Valid || claims.ExpiresAt == nil {
+		return &auth.ErrInvalidResetToken
+	}
+
+	// * Ссылка одноразовая: использованный jti лежит в списке отзыва
+	isRevoked, err := a.isTokenRevoked(ctx, claims.ID)
 	if err != nil {
-		return "", &types.Error{
-			Message: err.Error(),
-			Field:   "role-ids",
-			Tag:     "auth",
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "reset-password",
+			Tag:   "auth",
+			Cause: err,
 		}
 	}
+	if isRevoked {
+		return &auth.ErrInvalidResetToken
+	}
 
-	for _, inpRole := range inpRoles {
-		var userRoleID string
+	user, err := a.repo.GetUserById(ctx, claims.Subject)
+	if err != nil {
+		return &auth.ErrInvalidResetToken
+	}
 
-		for _, currUserRole := range currUserRoles {
-			if currUserRole.RoleID == inpRole.ID {
-				userRoleID = currUserRole.ID
+	if claims.PasswordFingerprint != passwordFingerprint(user.Password) {
+		return &auth.ErrInvalidResetToken
+	}
+
+	hashPassword, err := HashPassword(inp.Password)
+	if err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "reset-password",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	hashPasswordConfirm, err := HashPassword(inp.PasswordConfirm)
+	if err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "reset-password",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	user.Password = hashPassword
+	user.PasswordConfirm = hashPasswordConfirm
+	// * Блокировку мог накрутить кто угодно неверными паролями, после сброса она снимается
+	user.SignInLock = models.SignInLock{}
+	if err := a.repo.UpdateUser(ctx, user); err != nil {
+		return &auth.ErrCantUpdateUser
+	}
+
+	if err := a.revokeToken(ctx, user.ID, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "reset-password",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	// * Пароль мог утечь, поэтому выкидываем юзера со всех устройств
+	if err := a.revokeUserSessions(ctx, user.ID); err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "reset-password",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+	a.userCache.Delete(user.ID)
+
+	return nil
+}
+
+// ChangePassword меняет пароль, закрывает все сессии и выдает новую пару токенов текущему клиенту
+func (a *UseCase) ChangePassword(ctx context.Context, inp *auth.ChangePasswordInput) (*auth.Tokens, *types.Error) {
+	user, err := a.repo.GetUserById(ctx, inp.ID)
+	if err != nil {
+		return nil, &auth.ErrUserNotFound
+	}
+
+	if isEqual, _ := ComparePasswordHash(user.Password, inp.OldPassword); !isEqual {
+		return nil, &auth.ErrEmailOrPassword
+	}
+
+	hashPassword, err := HashPassword(inp.Password)
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "change-password",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	hashPasswordConfirm, err := HashPassword(inp.PasswordConfirm)
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "change-password",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	user.Password = hashPassword
+	user.PasswordConfirm = hashPasswordConfirm
+	if err := a.repo.UpdateUser(ctx, user); err != nil {
+		return nil, &auth.ErrCantUpdateUser
+	}
+
+	if err := a.revokeUserSessions(ctx, user.ID); err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "change-password",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+	a.userCache.Delete(user.ID)
+
+	return a.CreateSession(ctx, user)
+}
+
+// ChangeEmail отправляет код подтверждения на новый адрес, сам email меняется в ConfirmChangeEmail
+func (a *UseCase) ChangeEmail(ctx context.Context, inp *auth.ChangeEmailInput) *types.Error {
+	user, err := a.repo.GetUserById(ctx, inp.ID)
+	if err != nil {
+		return &auth.ErrUserNotFound
+	}
+
+	if isEqual, _ := ComparePasswordHash(user.Password, inp.Password); !isEqual {
+		return &auth.ErrEmailOrPassword
+	}
+
+	if user.Email == inp.Email {
+		return &auth.ErrEmailIsSame
+	}
+
+	if _, err := a.repo.GetUserByEmail(ctx, inp.Email); err == nil {
+		return &auth.ErrUserIsExist
+	}
+
+	// * Новый адрес — новый код, но блокировка и кулдаун переносятся
+	if user.EmailChange.Email != inp.Email {
+		user.EmailChange = models.EmailChange{
+			Email: inp.Email,
+			Code: models.VerifyCode{
+				SentAt:      user.EmailChange.Code.SentAt,
+				LockedUntil: user.EmailChange.Code.LockedUntil,
+			},
+		}
+	}
+
+	verifyCode, codeErr := a.issueVerifyCode(&user.EmailChange.Code)
+	if codeErr != nil {
+		return codeErr
+	}
+
+	if err := a.repo.UpdateUser(ctx, user); err != nil {
+		return &auth.ErrCantUpdateUser
+	}
+
+	// Отправляем письмо на новый адрес
+	locale := i18n.Preferred(ctx, user.Locale)
+	emailMessage := service_email.Message{
+		Subject:      i18n.T(locale, "email.change_email.subject"),
+		To:           []string{inp.Email},
+		TemplateName: "VerifyCode",
+		Locale:       locale,
+		Content: EmailContent{
+			VerifyCode: verifyCode,
+		},
+	}
+	a.mailer.Send(&emailMessage)
+
+	return nil
+}
+
+type EmailChangedContent struct {
+	NewEmail string
+}
+
+func (a *UseCase) ConfirmChangeEmail(ctx context.Context, inp *auth.ConfirmChangeEmailInput) *types.Error {
+	user, err := a.repo.GetUserById(ctx, inp.ID)
+	if err != nil {
+		return &auth.ErrUserNotFound
+	}
+
+	if user.EmailChange.Email == "" {
+		return &auth.ErrEmailChangeNotRequested
+	}
+
+	if codeErr := a.checkVerifyCode(ctx, user.ID, auth.VerifyCodeEmailChange, &user.EmailChange.Code, inp.VerifyCode); codeErr != nil {
+		return codeErr
+	}
+
+	// * Пока ждали код, адрес мог занять кто-то другой
+	if _, err := a.repo.GetUserByEmail(ctx, user.EmailChange.Email); err == nil {
+		return &auth.ErrUserIsExist
+	}
+
+	oldEmail := user.Email
+	user.Email = user.EmailChange.Email
+	user.EmailChange = models.EmailChange{}
+
+	err = a.repo.UpdateUser(ctx, user)
+	if errors.Is(err, auth.ErrEmailIsTaken) {
+		return &auth.ErrUserIsExist
+	}
+	if err != nil {
+		return &auth.ErrCantUpdateUser
+	}
+	a.userCache.Delete(user.ID)
+
+	// Предупреждаем старый адрес
+	locale := i18n.Preferred(ctx, user.Locale)
+	emailMessage := service_email.Message{
+		Subject:      i18n.T(locale, "email.email_changed.subject"),
+		To:           []string{oldEmail},
+		TemplateName: "EmailChanged",
+		Locale:       locale,
+		Content: EmailChangedContent{
+			NewEmail: user.Email,
+		},
+	}
+	a.mailer.Send(&emailMessage)
+
+	return nil
+}
+
+func passwordFingerprint(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+func (a *UseCase) GetProfile(ctx context.Context, inp *auth.GetProfileInput) (*models.User, *types.Error) {
+	user, err := a.repo.GetUserById(ctx, inp.ID)
+
+	if err != nil {
+		return nil, &auth.ErrUserNotFound
+	}
+
+	user, err = a.MakeClearUser(ctx, user)
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "get-profile",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	return user, nil
+}
+
+func (a *UseCase) UpdateProfile(ctx context.Context, inp *auth.UpdateProfileInput) (string, *types.Error) {
+	var user *models.User
+
+	// * Роли и юзер меняются вместе: при сбое не остается созданных или удаленных ролей без юзера.
+	// * Юзера читаем внутри транзакции, при повторе fn он перечитывается заново
+	txErr := a.inTransaction(ctx, "update-profile", func(ctx context.Context) *types.Error {
+		// * Получаем юзера
+		var err error
+		user, err = a.repo.GetUserById(ctx, inp.ID)
+		if err != nil {
+			return &auth.ErrUserNotFound
+		}
+
+		// * Закончили регистрацию до конца
+		user.FinishedRegistration = true
+
+		user.IIN = inp.IIN
+		user.Name = inp.Name
+		user.Surname = inp.Surname
+		user.Birthday = inp.Birthday
+		user.Gender = inp.Gender
+		user.Address = models.Address(inp.Address)
+		if inp.Locale != "" {
+			user.Locale = inp.Locale
+		}
+
+		// * Находим роль юзера
+		roleUser, err := a.roleRepo.GetRoleByName(ctx, string(models.RoleNameUser))
+		if err != nil {
+			return &types.Error{
+				Code:  errs.Internal,
+				Field: "update-profile",
+				Tag:   "auth",
+				Cause: err,
 			}
+		}
 
-			if roleUser.ID == inpRole.ID {
-				continue
+		var newUserRoleIDs []string
+		// * Все текущие юзер-роли у юзера
+		currUserRoles, err := a.userRoleRepo.GetUserRoleByIDs(ctx, user.UserRoleIDs)
+		if err != nil {
+			return &types.Error{
+				Code:  errs.Internal,
+				Field: "user-role-ids",
+				Tag:   "auth",
+				Cause: err,
 			}
 		}
 
-		// * Сохраняем старую роль
-		if userRoleID != "" {
-			newUserRoleIDs = append(newUserRoleIDs, userRoleID)
-		} else {
-			// * Создаем новую роль
-			userRoleId := primitive.NewObjectID()
-
-			// Создаем юзер.роль
-			userRole := models.UserRole{
-				ID:     userRoleId.Hex(),
-				UserID: user.ID,
-				RoleID: inpRole.ID,
-				// * У всех новых ролей, статус будет запрошенным
-				Status:    models.UserRoleStatusPending,
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
+		for _, currUserRole := range currUserRoles {
+			if currUserRole.RoleID == roleUser.ID {
+				newUserRoleIDs = []string{currUserRole.ID}
 			}
-			err = a.userRoleRepo.CreateUserRole(ctx, &userRole)
-			if err != nil {
-				return "", &types.Error{
-					Message: err.Error(),
-					Field:   "update-profile",
-					Tag:     "auth",
+		}
+
+		// * Находим все роли из запроса
+		inpRoles, err := a.roleRepo.GetRoleByIDs(ctx, inp.RoleIDs)
+		if err != nil {
+			return &types.Error{
+				Code:  errs.Internal,
+				Field: "role-ids",
+				Tag:   "auth",
+				Cause: err,
+			}
+		}
+
+		for _, inpRole := range inpRoles {
+			var userRoleID string
+
+			for _, currUserRole := range currUserRoles {
+				if currUserRole.RoleID == inpRole.ID {
+					userRoleID = currUserRole.ID
+				}
+
+				if roleUser.ID == inpRole.ID {
+					continue
 				}
 			}
 
-			newUserRoleIDs = append(newUserRoleIDs, userRoleId.Hex())
+			// * Сохраняем старую роль
+			if userRoleID != "" {
+				newUserRoleIDs = append(newUserRoleIDs, userRoleID)
+			} else {
+				// * Создаем новую роль
+				userRoleId := primitive.NewObjectID()
+
+				// Создаем юзер.роль
+				userRole := models.UserRole{
+					ID:     userRoleId.Hex(),
+					UserID: user.ID,
+					RoleID: inpRole.ID,
+					// * У всех новых ролей, статус будет запрошенным
+					Status:    models.UserRoleStatusPending,
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				}
+				err = a.userRoleRepo.CreateUserRole(ctx, &userRole)
+				if err != nil {
+					return &types.Error{
+						Code:  errs.Internal,
+						Field: "update-profile",
+						Tag:   "auth",
+						Cause: err,
+					}
+				}
+
+				newUserRoleIDs = append(newUserRoleIDs, userRoleId.Hex())
+			}
 		}
-	}
 
-	for _, currUserRole := range currUserRoles {
-		var isDeleteUserRole bool = true
+		for _, currUserRole := range currUserRoles {
+			var isDeleteUserRole bool = true
+
+			for _, newUserRoleID := range newUserRoleIDs {
+				if currUserRole.ID == newUserRoleID {
+					isDeleteUserRole = false
+				}
+			}
+
+			if isDeleteUserRole {
+				err = a.userRoleRepo.DeleteUserRoleByID(ctx, currUserRole.ID)
+				if err != nil {
+					return &auth.ErrCantDeleteUserRole
+				}
+			}
+		}
 
-		for _, newUserRoleID := range newUserRoleIDs {
-			if currUserRole.ID == newUserRoleID {
-				isDeleteUserRole = false
+		// * Обновляем юзера
+		user.UserRoleIDs = newUserRoleIDs
+		user.RolesVersion++
+		if err = a.repo.UpdateUser(ctx, user); err != nil {
+			// * ИИН уникален, второй аккаунт с тем же ИИН упирается в индекс
+			if errors.Is(err, auth.ErrIINIsTaken) {
+				return &auth.ErrIINIsExist
 			}
+			return &auth.ErrCantUpdateUser
 		}
 
-		if isDeleteUserRole {
-			err = a.userRoleRepo.DeleteUserRoleByID(ctx, currUserRole.ID)
-			if err != nil {
-				return "", &auth.ErrCantDeleteUserRole
+		return nil
+	})
+	if txErr != nil {
+		return "", txErr
+	}
+	a.userCache.Delete(user.ID)
+
+	return a.replaceToken(ctx, user, inp.Token)
+}
+
+// replaceToken выдает новый access token в рамках той же сессии, а старый отзывает
+func (a *UseCase) replaceToken(ctx context.Context, user *models.User, token *auth.AccessToken) (string, *types.Error) {
+	session := models.Session{
+		FamilyID: token.SessionID,
+	}
+
+	accessToken, tokenErr := a.GetToken(ctx, user, &session)
+	if tokenErr != nil {
+		return "", tokenErr
+	}
+
+	if token.SessionID != "" {
+		if err := a.sessionRepo.SetSessionAccessTokenID(ctx, token.SessionID, session.AccessTokenID); err != nil {
+			return "", &types.Error{
+				Code:  errs.Internal,
+				Field: "create-token",
+				Tag:   "auth",
+				Cause: err,
 			}
 		}
 	}
 
-	// * Обновляем юзера
-	user.UserRoleIDs = newUserRoleIDs
-	if err = a.repo.UpdateUser(ctx, user); err != nil {
-		return "", &auth.ErrCantUpdateUser
+	if err := a.revokeToken(ctx, user.ID, token.ID, token.ExpiresAt); err != nil {
+		return "", &types.Error{
+			Code:  errs.Internal,
+			Field: "create-token",
+			Tag:   "auth",
+			Cause: err,
+		}
 	}
 
-	return a.GetToken(ctx, user)
-}
\ No newline at end of file
+	return accessToken, nil
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"health/models"
+	"health/routes/client/auth"
+	"health/shared/errs"
+	"health/shared/types"
+	"math/big"
+	"time"
+)
+
+// VerifyCodePolicy — ограничения на коды из писем
+type VerifyCodePolicy struct {
+	TTL            time.Duration
+	ResendCooldown time.Duration
+	MaxAttempts    int
+	Lockout        time.Duration
+}
+
+const verifyCodeLength = 6
+
+// generateVerifyCode — случайный код из verifyCodeLength цифр, с ведущими нулями
+func generateVerifyCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", verifyCodeLength, n.Int64()), nil
+}
+
+// issueVerifyCode создает новый код, записывает в code его хеш и возвращает сам код для письма
+func (a *UseCase) issueVerifyCode(code *models.VerifyCode) (string, *types.Error) {
+	now := time.Now()
+
+	if now.Before(code.LockedUntil) {
+		return "", auth.ErrVerifyCodeLocked.WithRetryAfter(code.LockedUntil.Sub(now))
+	}
+
+	if resendAt := code.SentAt.Add(a.verifyCodePolicy.ResendCooldown); now.Before(resendAt) {
+		return "", auth.ErrVerifyCodeResendTooSoon.WithRetryAfter(resendAt.Sub(now))
+	}
+
+	plainCode, err := generateVerifyCode()
+	if err != nil {
+		return "", &types.Error{
+			Code:  errs.Internal,
+			Field: "verify-code",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	hash, err := HashPassword(plainCode)
+	if err != nil {
+		return "", &types.Error{
+			Code:  errs.Internal,
+			Field: "verify-code",
+			Tag:   "auth",
+			Cause: err,
+		}
+	}
+
+	*code = models.VerifyCode{
+		Hash:      hash,
+		ExpiresAt: now.Add(a.verifyCodePolicy.TTL),
+		SentAt:    now,
+	}
+
+	return plainCode, nil
+}
+
+// checkVerifyCode сверяет код field юзера userID. Попытка засчитывается в базе до сверки,
+// атомарно и с проверкой лимита, поэтому параллельные запросы не получат больше MaxAttempts попыток.
+// На успехе код в code очищается, его нужно сохранить вместе с остальными изменениями юзера.
+func (a *UseCase) checkVerifyCode(ctx context.Context, userID string, field auth.VerifyCodeField, code *models.VerifyCode, plainCode string) *types.Error {
+	now := time.Now()
+
+	if now.Before(code.LockedUntil) {
+		return auth.ErrVerifyCodeLocked.WithRetryAfter(code.LockedUntil.Sub(now))
+	}
+
+	if code.Hash == "" || now.After(code.ExpiresAt) {
+		return &auth.ErrVerifyCodeExpired
+	}
+
+	attempt, err := a.repo.RecordVerifyCodeAttempt(ctx, userID, field, code.Hash, a.verifyCodePolicy.MaxAttempts, now.Add(a.verifyCodePolicy.Lockout))
+	if err != nil {
+		return &auth.ErrCantUpdateUser
+	}
+	// * Попытки по этому коду кончились в параллельном запросе, либо код уже сменили
+	if attempt == 0 {
+		return auth.ErrVerifyCodeTooManyAttempts.WithRetryAfter(a.verifyCodePolicy.Lockout)
+	}
+
+	if isEqual, _ := ComparePasswordHash(code.Hash, plainCode); !isEqual {
+		// * Последняя попытка заблокировала код, новый можно запросить только после блокировки
+		if attempt >= a.verifyCodePolicy.MaxAttempts {
+			return auth.ErrVerifyCodeTooManyAttempts.WithRetryAfter(a.verifyCodePolicy.Lockout)
+		}
+
+		return &auth.ErrVerifyCodeNotMatch
+	}
+
+	*code = models.VerifyCode{}
+
+	return nil
+}
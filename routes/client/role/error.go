@@ -1,17 +1,38 @@
 package role
 
 import (
+	"health/shared/errs"
 	"health/shared/types"
 )
 
 var (
 	ErrCantFindRole = types.Error{
+		Code:    errs.RoleNotFound,
 		Message: "Cant find role",
 		Field:   "id",
 		Tag:     "role",
 	}
+	ErrRoleNameIsExist = types.Error{
+		Code:    errs.RoleNameExists,
+		Message: "Role with this name already exists",
+		Field:   "name",
+		Tag:     "role",
+	}
+	ErrRoleIsInUse = types.Error{
+		Code:    errs.RoleInUse,
+		Message: "Role is assigned to users and can`t be deleted",
+		Field:   "id",
+		Tag:     "role",
+	}
+	ErrRoleIsSystem = types.Error{
+		Code:    errs.RoleIsSystem,
+		Message: "System role can`t be deleted or renamed",
+		Field:   "id",
+		Tag:     "role",
+	}
 	ErrUserIsUnauthorized = types.Error{
-		Message: "User is unauthorized",
+		Code:    errs.RoleForbidden,
+		Message: "Not enough permissions",
 		Field:   "id",
 		Tag:     "role",
 	}
@@ -0,0 +1,42 @@
+package roleHandler
+
+import (
+	"health/models"
+	"health/routes/client/role"
+	"health/services/openapi"
+	"health/shared/errs"
+	"net/http"
+)
+
+const docsTag = "role"
+
+// Endpoints — описание роутов из RegisterHTTPEndpoints для OpenAPI спецификации
+func (m *Module) Endpoints() []openapi.Endpoint {
+	manage := string(models.PermissionRoleManage)
+
+	return []openapi.Endpoint{
+		{
+			Method: http.MethodGet, Path: "/api/role/v1/list", Tag: docsTag, Summary: "All roles",
+			Data: RolesResponse{},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/role/v1/create", Tag: docsTag, Summary: "Create a role",
+			Auth: true, Permission: manage,
+			Body:   role.CreateRoleInput{},
+			Data:   RoleResponse{},
+			Errors: []errs.Code{errs.RoleNameExists},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/role/v1/update/:id", Tag: docsTag, Summary: "Rename a role or change its permissions",
+			Auth: true, Permission: manage,
+			Body:   role.UpdateRoleInput{},
+			Data:   RoleResponse{},
+			Errors: []errs.Code{errs.RoleNotFound, errs.RoleIsSystem, errs.RoleNameExists},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/role/v1/delete/:id", Tag: docsTag, Summary: "Delete a role nobody has",
+			Auth: true, Permission: manage,
+			Errors: []errs.Code{errs.RoleNotFound, errs.RoleIsSystem, errs.RoleInUse},
+		},
+	}
+}
@@ -2,6 +2,7 @@ package roleHandler
 
 import (
 	"health/routes/client/role"
+	"health/services/validation"
 	"health/shared/types"
 	"net/http"
 
@@ -21,17 +22,67 @@ func NewHandler(useCase role.UseCase) *Handler {
 func (h *Handler) GetRoles(c *gin.Context) {
 	roles, err := h.useCase.GetRoles(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, types.GoodResponse{
 		Code: http.StatusOK,
-		Data: map[string]interface{}{
-			"roles": roles,
+		Data: RolesResponse{
+			Roles: roles,
 		},
 	})
 }
+
+func (h *Handler) CreateRole(c *gin.Context) {
+	inp := new(role.CreateRoleInput)
+
+	if !validation.BindJSON(c, inp, "role") {
+		return
+	}
+
+	roleEntity, err := h.useCase.CreateRole(c.Request.Context(), inp)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.GoodResponse{
+		Code: http.StatusOK,
+		Data: RoleResponse{
+			Role: roleEntity,
+		},
+	})
+}
+
+func (h *Handler) UpdateRole(c *gin.Context) {
+	inp := new(role.UpdateRoleInput)
+
+	if !validation.BindJSON(c, inp, "role") {
+		return
+	}
+
+	inp.ID = c.Param("id")
+
+	roleEntity, err := h.useCase.UpdateRole(c.Request.Context(), inp)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.GoodResponse{
+		Code: http.StatusOK,
+		Data: RoleResponse{
+			Role: roleEntity,
+		},
+	})
+}
+
+func (h *Handler) DeleteRole(c *gin.Context) {
+	if err := h.useCase.DeleteRole(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -1,59 +1,49 @@
 package roleHandler
 
 import (
-	"fmt"
 	"health/models"
 	"health/routes/client/auth"
 	"health/routes/client/role"
-	"health/shared/types"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Middleware пропускает юзера, если его одобренные роли подходят под условие.
+// Юзер с ролями кладется в контекст auth middleware, поэтому права всегда актуальные.
 type Middleware struct {
-	roleName models.RoleName
-	status   models.UserRoleStatus
+	allow func(user *models.User) bool
 }
 
-func NewMiddlewareUser(usecase role.UseCase) gin.HandlerFunc {
-	return (NewMiddleware(models.RoleNameUser, models.UserRoleStatusApproved)).HandleMiddleware
+// RequireAnyRole — нужна хотя бы одна из ролей
+func RequireAnyRole(names ...models.RoleName) gin.HandlerFunc {
+	return (&Middleware{
+		allow: func(user *models.User) bool {
+			return user.HasAnyRole(names...)
+		},
+	}).Handle
 }
 
-func NewMiddlewareSpecialist(usecase role.UseCase) gin.HandlerFunc {
-	return (NewMiddleware(models.RoleNameSpecialist, models.UserRoleStatusApproved)).HandleMiddleware
+// RequirePermission — нужны все перечисленные права
+func RequirePermission(permissions ...models.Permission) gin.HandlerFunc {
+	return (&Middleware{
+		allow: func(user *models.User) bool {
+			for _, permission := range permissions {
+				if !user.HasPermission(permission) {
+					return false
+				}
+			}
+
+			return true
+		},
+	}).Handle
 }
 
-func NewMiddlewareMinion(usecase role.UseCase) gin.HandlerFunc {
-	return (NewMiddleware(models.RoleNameMinion, models.UserRoleStatusApproved)).HandleMiddleware
-}
-
-func NewMiddleware(roleName models.RoleName, status models.UserRoleStatus) *Middleware {
-	return &Middleware{roleName: roleName, status: status}
-}
-
-func (m *Middleware) HandleMiddleware(c *gin.Context) {
-	var userRoles []*models.UserRoleWithRole
-
-	if user, exist := c.Get(auth.CtxUserKey); exist {
-		userRoles = user.(*models.User).UserRoles
+func (m *Middleware) Handle(c *gin.Context) {
+	if user, exist := c.Get(auth.CtxUserKey); exist && m.allow(user.(*models.User)) {
+		c.Next()
+		return
 	}
 
-	fmt.Println("HandleMiddleware", userRoles)
-
-	for _, userRole := range userRoles {
-		isRole, isApproved :=
-			userRole.Name == m.roleName, userRole.Status == m.status
-
-		if isRole && isApproved {
-			c.Next()
-			return
-		}
-	}
-
-	c.JSON(http.StatusUnauthorized, types.BadResponse{
-		Code:  http.StatusUnauthorized,
-		Error: &role.ErrUserIsUnauthorized,
-	})
+	c.Error(&role.ErrUserIsUnauthorized)
 	c.Abort()
-}
\ No newline at end of file
+}
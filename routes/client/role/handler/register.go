@@ -1,38 +1,38 @@
 package roleHandler
 
 import (
-	authRepository "health/routes/client/auth/repository"
-	"health/routes/client/role/repository"
-	"health/routes/client/role/usecase"
+	"health/models"
+	"health/routes/client/role"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func RegisterHTTPEndpoints(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, db *mongo.Database) (gin.HandlerFunc, gin.HandlerFunc, gin.HandlerFunc) {
-	// Создаем repository, все взаимодействия с db в ней
-	repo := repository.NewRepository(db)
-	authRepository := authRepository.NewRepository(db)
+type Module struct {
+	handler        *Handler
+	authMiddleware gin.HandlerFunc
+}
 
-	// Создаем usecase, вся бизнес-логика в нем
-	uc := usecase.NewUseCase(
-		repo,
-		authRepository,
-	)
+func NewModule(uc role.UseCase, authMiddleware gin.HandlerFunc) *Module {
+	return &Module{
+		handler:        NewHandler(uc),
+		authMiddleware: authMiddleware,
+	}
+}
 
-	// Create the middleware instance
-	mUser := NewMiddlewareUser(uc)
-	mSpecialist := NewMiddlewareSpecialist(uc)
-	mMinion := NewMiddlewareMinion(uc)
+func (m *Module) RegisterHTTPEndpoints(router *gin.RouterGroup) {
+	h, authMiddleware := m.handler, m.authMiddleware
 
-	// Create the handler
-	h := NewHandler(uc)
+	// Create the middleware instance
+	canManageRoles := RequirePermission(models.PermissionRoleManage)
 
 	// Create the endpoints
-	endpoints := router.Group("/role/v1")
+	endpoints := router.Group("/api/role/v1")
 	{
 		endpoints.GET("/list", h.GetRoles)
-	}
 
-	return mUser, mSpecialist, mMinion
+		// * управление ролями только с правом role:manage
+		endpoints.POST("/create", authMiddleware, canManageRoles, h.CreateRole)
+		endpoints.POST("/update/:id", authMiddleware, canManageRoles, h.UpdateRole)
+		endpoints.POST("/delete/:id", authMiddleware, canManageRoles, h.DeleteRole)
+	}
 }
@@ -0,0 +1,11 @@
+package roleHandler
+
+import "health/models"
+
+type RolesResponse struct {
+	Roles []*models.Role `json:"roles"`
+}
+
+type RoleResponse struct {
+	Role *models.Role `json:"role"`
+}
@@ -2,12 +2,19 @@ package role
 
 import (
 	"context"
+	"errors"
 	"health/models"
 )
 
+// ErrRoleNameIsTaken — CreateRole/UpdateRole нарушили уникальный индекс имени
+var ErrRoleNameIsTaken = errors.New("role name is taken")
+
 type Repository interface {
 	GetRoleByName(ctx context.Context, name string) (*models.Role, error)
 	GetRoleByID(ctx context.Context, id string) (*models.Role, error)
 	GetRoleByIDs(ctx context.Context, id []string) ([]*models.Role, error)
 	GetRoles(ctx context.Context) ([]*models.Role, error)
+	CreateRole(ctx context.Context, role *models.Role) error
+	UpdateRole(ctx context.Context, role *models.Role) error
+	DeleteRole(ctx context.Context, id string) error
 }
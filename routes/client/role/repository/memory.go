@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"health/models"
+	"health/routes/client/role"
+	"health/shared/errs"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryRepository — роли в памяти процесса, ошибки те же, что у монги
+type MemoryRepository struct {
+	mu    sync.RWMutex
+	roles map[string]*models.Role
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		roles: make(map[string]*models.Role),
+	}
+}
+
+func (r *MemoryRepository) GetRoleByID(ctx context.Context, id string) (*models.Role, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roleEntity, ok := r.roles[id]
+	if !ok {
+		return nil, errs.ErrNotFound
+	}
+
+	return copyRole(roleEntity), nil
+}
+
+func (r *MemoryRepository) GetRoleByIDs(ctx context.Context, ids []string) ([]*models.Role, error) {
+	for _, id := range ids {
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := []*models.Role{}
+	for _, roleEntity := range r.sortedRoles() {
+		for _, id := range ids {
+			if roleEntity.ID == id {
+				roles = append(roles, copyRole(roleEntity))
+				break
+			}
+		}
+	}
+
+	return roles, nil
+}
+
+func (r *MemoryRepository) GetRoleByName(ctx context.Context, name string) (*models.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roleEntity := r.findByName(models.RoleName(name))
+	if roleEntity == nil {
+		return nil, errs.ErrNotFound
+	}
+
+	return copyRole(roleEntity), nil
+}
+
+func (r *MemoryRepository) GetRoles(ctx context.Context) ([]*models.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var roles []*models.Role
+	for _, roleEntity := range r.sortedRoles() {
+		roles = append(roles, copyRole(roleEntity))
+	}
+
+	return roles, nil
+}
+
+func (r *MemoryRepository) CreateRole(ctx context.Context, roleEntity *models.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.findByName(roleEntity.Name) != nil {
+		return role.ErrRoleNameIsTaken
+	}
+
+	roleEntity.CreatedAt = time.Now()
+	roleEntity.UpdatedAt = time.Now()
+	roleEntity.ID = primitive.NewObjectID().Hex()
+
+	r.roles[roleEntity.ID] = copyRole(roleEntity)
+
+	return nil
+}
+
+func (r *MemoryRepository) UpdateRole(ctx context.Context, roleEntity *models.Role) error {
+	if _, err := primitive.ObjectIDFromHex(roleEntity.ID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.roles[roleEntity.ID]
+	if !ok {
+		return errs.ErrNotFound
+	}
+
+	if other := r.findByName(roleEntity.Name); other != nil && other.ID != roleEntity.ID {
+		return role.ErrRoleNameIsTaken
+	}
+
+	roleEntity.UpdatedAt = time.Now()
+
+	updated := copyRole(roleEntity)
+	updated.CreatedAt = stored.CreatedAt
+	r.roles[roleEntity.ID] = updated
+
+	return nil
+}
+
+func (r *MemoryRepository) DeleteRole(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.roles[id]; !ok {
+		return errs.ErrNotFound
+	}
+
+	delete(r.roles, id)
+
+	return nil
+}
+
+func (r *MemoryRepository) findByName(name models.RoleName) *models.Role {
+	for _, roleEntity := range r.roles {
+		if roleEntity.Name == name {
+			return roleEntity
+		}
+	}
+
+	return nil
+}
+
+// sortedRoles — в порядке создания, как их отдает монга без сортировки
+func (r *MemoryRepository) sortedRoles() []*models.Role {
+	roles := make([]*models.Role, 0, len(r.roles))
+	for _, roleEntity := range r.roles {
+		roles = append(roles, roleEntity)
+	}
+
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].ID < roles[j].ID
+	})
+
+	return roles
+}
+
+func copyRole(i *models.Role) *models.Role {
+	roleEntity := *i
+	roleEntity.Permissions = append([]models.Permission{}, i.Permissions...)
+
+	return &roleEntity
+}
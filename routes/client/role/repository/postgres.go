@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"health/models"
+	"health/routes/client/role"
+	"health/services/transaction"
+	"health/shared/errs"
+	"health/shared/utils"
+	"time"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const roleColumns = `id, is_default, name, permissions, created_at, updated_at`
+
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresRepository) GetRoleByID(ctx context.Context, id string) (*models.Role, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+
+	row := transaction.SQLExecutor(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+roleColumns+` FROM roles WHERE id = $1`,
+		id,
+	)
+
+	return scanRole(row)
+}
+
+func (r *PostgresRepository) GetRoleByIDs(ctx context.Context, ids []string) ([]*models.Role, error) {
+	if len(ids) == 0 {
+		return []*models.Role{}, nil
+	}
+
+	for _, id := range ids {
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.queryRoles(ctx, `SELECT `+roleColumns+` FROM roles WHERE id = ANY($1) ORDER BY id`, pq.Array(ids))
+}
+
+func (r *PostgresRepository) GetRoleByName(ctx context.Context, name string) (*models.Role, error) {
+	row := transaction.SQLExecutor(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+roleColumns+` FROM roles WHERE name = $1`,
+		name,
+	)
+
+	return scanRole(row)
+}
+
+func (r *PostgresRepository) GetRoles(ctx context.Context) ([]*models.Role, error) {
+	return r.queryRoles(ctx, `SELECT `+roleColumns+` FROM roles ORDER BY id`)
+}
+
+func (r *PostgresRepository) CreateRole(ctx context.Context, roleEntity *models.Role) error {
+	roleEntity.CreatedAt = time.Now()
+	roleEntity.UpdatedAt = time.Now()
+	roleEntity.ID = primitive.NewObjectID().Hex()
+
+	_, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO roles (`+roleColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+		roleEntity.ID, roleEntity.IsDefault, string(roleEntity.Name), pq.Array(permissionsToStrings(roleEntity.Permissions)),
+		roleEntity.CreatedAt, roleEntity.UpdatedAt,
+	)
+	if utils.IsUniqueViolation(err) {
+		return role.ErrRoleNameIsTaken
+	}
+
+	return err
+}
+
+func (r *PostgresRepository) UpdateRole(ctx context.Context, roleEntity *models.Role) error {
+	if _, err := primitive.ObjectIDFromHex(roleEntity.ID); err != nil {
+		return err
+	}
+
+	roleEntity.UpdatedAt = time.Now()
+
+	res, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx,
+		`UPDATE roles SET is_default = $2, name = $3, permissions = $4, updated_at = $5 WHERE id = $1`,
+		roleEntity.ID, roleEntity.IsDefault, string(roleEntity.Name), pq.Array(permissionsToStrings(roleEntity.Permissions)),
+		roleEntity.UpdatedAt,
+	)
+	if utils.IsUniqueViolation(err) {
+		return role.ErrRoleNameIsTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+func (r *PostgresRepository) DeleteRole(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return err
+	}
+
+	res, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx, `DELETE FROM roles WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res)
+}
+
+func (r *PostgresRepository) queryRoles(ctx context.Context, query string, args ...interface{}) ([]*models.Role, error) {
+	rows, err := transaction.SQLExecutor(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	for rows.Next() {
+		roleEntity, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, roleEntity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// requireAffected — как у монги, обновление или удаление несуществующей роли возвращает ошибку
+func requireAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRole(row rowScanner) (*models.Role, error) {
+	roleEntity := new(models.Role)
+
+	var name string
+	var permissions []string
+	err := row.Scan(&roleEntity.ID, &roleEntity.IsDefault, &name, pq.Array(&permissions), &roleEntity.CreatedAt, &roleEntity.UpdatedAt)
+	if err != nil {
+		return nil, utils.SQLNotFound(err)
+	}
+
+	roleEntity.Name = models.RoleName(name)
+	for _, permission := range permissions {
+		roleEntity.Permissions = append(roleEntity.Permissions, models.Permission(permission))
+	}
+
+	return roleEntity, nil
+}
+
+func permissionsToStrings(permissions []models.Permission) []string {
+	res := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		res = append(res, string(permission))
+	}
+
+	return res
+}
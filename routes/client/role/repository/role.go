@@ -3,6 +3,10 @@ package repository
 import (
 	"context"
 	"health/models"
+	"health/routes/client/role"
+	"health/shared/errs"
+	"health/shared/utils"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -32,7 +36,7 @@ func (r *Repository) GetRoleByID(ctx context.Context, id string) (*models.Role,
 	}
 	err = r.FindOne(ctx, filter).Decode(role)
 	if err != nil {
-		return nil, err
+		return nil, utils.MongoNotFound(err)
 	}
 
 	return mapToDomainModel(role), nil
@@ -90,7 +94,7 @@ func (r *Repository) GetRoleByName(ctx context.Context, name string) (*models.Ro
 	}
 
 	if err := r.FindOne(ctx, filter).Decode(role); err != nil {
-		return nil, err
+		return nil, utils.MongoNotFound(err)
 	}
 
 	return mapToDomainModel(role), nil
@@ -124,10 +128,81 @@ func (r Repository) GetRoles(ctx context.Context) ([]*models.Role, error) {
 	return roles, nil
 }
 
+func (r *Repository) CreateRole(ctx context.Context, roleEntity *models.Role) error {
+	roleEntity.CreatedAt = time.Now()
+	roleEntity.UpdatedAt = time.Now()
+
+	model := mapToMongoSchema(roleEntity)
+	res, err := r.InsertOne(ctx, model)
+	if utils.IsDuplicateKeyError(err) {
+		return role.ErrRoleNameIsTaken
+	}
+	if err != nil {
+		return err
+	}
+
+	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+		roleEntity.ID = oid.Hex()
+	}
+
+	return nil
+}
+
+func (r *Repository) UpdateRole(ctx context.Context, roleEntity *models.Role) error {
+	oid, err := primitive.ObjectIDFromHex(roleEntity.ID)
+	if err != nil {
+		return err
+	}
+
+	roleEntity.UpdatedAt = time.Now()
+
+	filter := bson.M{
+		"_id": oid,
+	}
+	update := bson.M{
+		"$set": mapToMongoSchema(roleEntity),
+	}
+
+	res, err := r.UpdateOne(ctx, filter, update)
+	if utils.IsDuplicateKeyError(err) {
+		return role.ErrRoleNameIsTaken
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteRole(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{
+		"_id": oid,
+	}
+
+	res, err := r.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
 func mapToMongoSchema(i *models.Role) *models.RoleDBSchema {
 	return &models.RoleDBSchema{
-		IsDefault: i.IsDefault,
-		Name:      i.Name,
+		IsDefault:   i.IsDefault,
+		Name:        i.Name,
+		Permissions: i.Permissions,
 
 		CreatedAt: i.CreatedAt,
 		UpdatedAt: i.UpdatedAt,
@@ -138,8 +213,9 @@ func mapToDomainModel(i *models.RoleDBSchema) *models.Role {
 	return &models.Role{
 		ID: i.ID.Hex(),
 
-		IsDefault: i.IsDefault,
-		Name:      i.Name,
+		IsDefault:   i.IsDefault,
+		Name:        i.Name,
+		Permissions: i.Permissions,
 
 		CreatedAt: i.CreatedAt,
 		UpdatedAt: i.UpdatedAt,
@@ -9,4 +9,13 @@ import (
 type UseCase interface {
 	GetRoles(ctx context.Context) ([]*models.Role, *types.Error)
 	GetRoleByID(ctx context.Context, id string) (*models.Role, *types.Error)
+
+	CreateRole(ctx context.Context, inp *CreateRoleInput) (*models.Role, *types.Error)
+	UpdateRole(ctx context.Context, inp *UpdateRoleInput) (*models.Role, *types.Error)
+	DeleteRole(ctx context.Context, id string) *types.Error
+
+	// SeedRoles создает недостающие роли из models.DefaultRoles, повторный вызов ничего не меняет
+	SeedRoles(ctx context.Context) error
+	// BootstrapAdmin выдает одобренную роль admin юзеру с этим email, если ее у него еще нет
+	BootstrapAdmin(ctx context.Context, email string) error
 }
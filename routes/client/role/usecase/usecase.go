@@ -2,22 +2,35 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"health/models"
+	"strings"
 
 	"health/routes/client/auth"
 	"health/routes/client/role"
+	"health/routes/client/userRole"
+	"health/shared/errs"
 	"health/shared/types"
+	"health/shared/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type UseCase struct {
-	repoRole role.Repository
-	repoUser auth.Repository
+	repoRole     role.Repository
+	repoUser     auth.Repository
+	repoUserRole userRole.Repository
+	// * Кеш юзеров с ролями и правами живет в auth
+	auth auth.UseCase
 }
 
-func NewUseCase(repoRole role.Repository, repoUser auth.Repository) *UseCase {
+func NewUseCase(repoRole role.Repository, repoUser auth.Repository, repoUserRole userRole.Repository, authUseCase auth.UseCase) *UseCase {
 	return &UseCase{
-		repoRole: repoRole,
-		repoUser: repoUser,
+		repoRole:     repoRole,
+		repoUser:     repoUser,
+		repoUserRole: repoUserRole,
+		auth:         authUseCase,
 	}
 }
 
@@ -26,9 +39,10 @@ func (a *UseCase) GetRoles(ctx context.Context) ([]*models.Role, *types.Error) {
 
 	if err != nil {
 		return nil, &types.Error{
-			Message: err.Error(),
-			Field:   "roles",
-			Tag:     "auth",
+			Code:  errs.Internal,
+			Field: "roles",
+			Tag:   "auth",
+			Cause: err,
 		}
 	}
 
@@ -40,11 +54,232 @@ func (a *UseCase) GetRoleByID(ctx context.Context, id string) (*models.Role, *ty
 
 	if err != nil {
 		return nil, &types.Error{
-			Message: err.Error(),
-			Field:   "id",
-			Tag:     "auth-role",
+			Code:  errs.Internal,
+			Field: "id",
+			Tag:   "auth-role",
+			Cause: err,
 		}
 	}
 
 	return role, nil
-}
\ No newline at end of file
+}
+
+func (a *UseCase) CreateRole(ctx context.Context, inp *role.CreateRoleInput) (*models.Role, *types.Error) {
+	name := normalizeRoleName(inp.Name)
+
+	if _, err := a.repoRole.GetRoleByName(ctx, string(name)); err == nil {
+		return nil, &role.ErrRoleNameIsExist
+	}
+
+	roleEntity := &models.Role{
+		Name:        name,
+		IsDefault:   inp.IsDefault,
+		Permissions: toPermissions(inp.Permissions),
+	}
+	err := a.repoRole.CreateRole(ctx, roleEntity)
+	if errors.Is(err, role.ErrRoleNameIsTaken) {
+		return nil, &role.ErrRoleNameIsExist
+	}
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "create-role",
+			Tag:   "role",
+			Cause: err,
+		}
+	}
+
+	return roleEntity, nil
+}
+
+func (a *UseCase) UpdateRole(ctx context.Context, inp *role.UpdateRoleInput) (*models.Role, *types.Error) {
+	roleEntity, err := a.repoRole.GetRoleByID(ctx, inp.ID)
+	if err != nil {
+		return nil, &role.ErrCantFindRole
+	}
+
+	name := normalizeRoleName(inp.Name)
+
+	if name != roleEntity.Name {
+		if roleEntity.Name.IsSystem() {
+			return nil, &role.ErrRoleIsSystem
+		}
+
+		if _, err := a.repoRole.GetRoleByName(ctx, string(name)); err == nil {
+			return nil, &role.ErrRoleNameIsExist
+		}
+	}
+
+	roleEntity.Name = name
+	roleEntity.IsDefault = inp.IsDefault
+	roleEntity.Permissions = toPermissions(inp.Permissions)
+
+	err = a.repoRole.UpdateRole(ctx, roleEntity)
+	if errors.Is(err, role.ErrRoleNameIsTaken) {
+		return nil, &role.ErrRoleNameIsExist
+	}
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "update-role",
+			Tag:   "role",
+			Cause: err,
+		}
+	}
+	// * Права роли лежат в кешированных юзерах, какие из них с этой ролью — не знаем
+	a.auth.ForgetAllUsers()
+
+	return roleEntity, nil
+}
+
+func (a *UseCase) DeleteRole(ctx context.Context, id string) *types.Error {
+	roleEntity, err := a.repoRole.GetRoleByID(ctx, id)
+	if err != nil {
+		return &role.ErrCantFindRole
+	}
+
+	if roleEntity.Name.IsSystem() {
+		return &role.ErrRoleIsSystem
+	}
+
+	// * Роль нельзя удалить, пока на нее ссылается хоть одна запись user.roles, включая заявки
+	count, err := a.repoUserRole.CountUserRolesByRoleID(ctx, roleEntity.ID)
+	if err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "delete-role",
+			Tag:   "role",
+			Cause: err,
+		}
+	}
+	if count > 0 {
+		return &role.ErrRoleIsInUse
+	}
+
+	if err := a.repoRole.DeleteRole(ctx, roleEntity.ID); err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "delete-role",
+			Tag:   "role",
+			Cause: err,
+		}
+	}
+
+	return nil
+}
+
+func (a *UseCase) SeedRoles(ctx context.Context) error {
+	roles, err := a.repoRole.GetRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[models.RoleName]*models.Role, len(roles))
+	for _, roleEntity := range roles {
+		existing[roleEntity.Name] = roleEntity
+	}
+
+	for _, defaultRole := range models.DefaultRoles {
+		if roleEntity, ok := existing[defaultRole.Name]; ok {
+			// * Системным ролям досыпаем недостающие права, остальные роли настраивает админ
+			if roleEntity.Name.IsSystem() && addPermissions(roleEntity, defaultRole.Permissions) {
+				if err := a.repoRole.UpdateRole(ctx, roleEntity); err != nil {
+					return fmt.Errorf("update role %s: %w", roleEntity.Name, err)
+				}
+			}
+
+			continue
+		}
+
+		roleEntity := defaultRole
+		// * Роль мог только что создать другой инстанс
+		err := a.repoRole.CreateRole(ctx, &roleEntity)
+		if errors.Is(err, role.ErrRoleNameIsTaken) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("create role %s: %w", roleEntity.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *UseCase) BootstrapAdmin(ctx context.Context, email string) error {
+	user, err := a.repoUser.GetUserByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("user %s not found: %w", email, err)
+	}
+
+	adminRole, err := a.repoRole.GetRoleByName(ctx, string(models.RoleNameAdmin))
+	if err != nil {
+		return errors.New("admin role not found, seed roles first")
+	}
+
+	userRoles, err := a.repoUserRole.GetUserRoleByIDs(ctx, user.UserRoleIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, userRoleEntity := range userRoles {
+		if userRoleEntity.RoleID != adminRole.ID {
+			continue
+		}
+
+		if userRoleEntity.Status == models.UserRoleStatusApproved {
+			return nil
+		}
+
+		// * Заявка на админа в ожидании или отклонена — заменяем одобренной
+		if err := a.repoUserRole.DeleteUserRoleByID(ctx, userRoleEntity.ID); err != nil {
+			return err
+		}
+		index, _ := utils.Find(user.UserRoleIDs, func(id string) bool { return id == userRoleEntity.ID })
+		if index != -1 {
+			user.UserRoleIDs = utils.RemoveElementByIndex(user.UserRoleIDs, index)
+		}
+	}
+
+	userRoleEntity := &models.UserRole{
+		ID:     primitive.NewObjectID().Hex(),
+		UserID: user.ID,
+		RoleID: adminRole.ID,
+		Status: models.UserRoleStatusApproved,
+	}
+	if err := a.repoUserRole.CreateUserRole(ctx, userRoleEntity); err != nil {
+		return err
+	}
+
+	user.UserRoleIDs = append(user.UserRoleIDs, userRoleEntity.ID)
+	user.RolesVersion++
+
+	return a.repoUser.UpdateUser(ctx, user)
+}
+
+// addPermissions добавляет роли недостающие права и сообщает, изменилось ли что-то
+func addPermissions(roleEntity *models.Role, permissions []models.Permission) bool {
+	isChanged := false
+
+	for _, permission := range permissions {
+		index, _ := utils.Find(roleEntity.Permissions, func(p models.Permission) bool { return p == permission })
+		if index == -1 {
+			roleEntity.Permissions = append(roleEntity.Permissions, permission)
+			isChanged = true
+		}
+	}
+
+	return isChanged
+}
+
+func toPermissions(permissions []string) []models.Permission {
+	res := make([]models.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		res = append(res, models.Permission(permission))
+	}
+
+	return res
+}
+
+func normalizeRoleName(name string) models.RoleName {
+	return models.RoleName(strings.ToLower(strings.TrimSpace(name)))
+}
@@ -1,12 +1,5 @@
 package role
 
-import (
-	"fmt"
-	"health/shared/types"
-
-	"github.com/go-playground/validator"
-)
-
 type RoleInput struct {
 	ID    string `json:"_id,omitempty"`
 	Email string `json:"email"`
@@ -14,22 +7,16 @@ type RoleInput struct {
 	RoleID string `json:"roleId,omitempty" validate:"required"`
 }
 
-func ValidateRoleInput(inp *RoleInput) *types.Error {
-	validate := validator.New()
-	err := validate.Struct(inp)
+type CreateRoleInput struct {
+	Name        string   `json:"name"        validate:"required,min=2,max=32"`
+	IsDefault   bool     `json:"isDefault"`
+	Permissions []string `json:"permissions" validate:"dive,permission"`
+}
 
-	if err != nil {
-		for _, err := range err.(validator.ValidationErrors) {
-			switch err.Tag() {
-			case "required":
-				return &types.Error{
-					Message: fmt.Sprintf("%s is required", err.Field()),
-					Field:   "id",
-					Tag:     "role",
-				}
-			}
-		}
-	}
+type UpdateRoleInput struct {
+	ID string `json:"-"` // id роли из пути
 
-	return nil
-}
\ No newline at end of file
+	Name        string   `json:"name"        validate:"required,min=2,max=32"`
+	IsDefault   bool     `json:"isDefault"`
+	Permissions []string `json:"permissions" validate:"dive,permission"`
+}
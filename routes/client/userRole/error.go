@@ -1,23 +1,57 @@
 package userRole
 
 import (
+	"health/shared/errs"
 	"health/shared/types"
 )
 
 var (
 	ErrCantFindRole = types.Error{
+		Code:    errs.UserRoleRoleNotFound,
 		Message: "Can`t find role",
 		Field:   "role_id",
 		Tag:     "user-role",
 	}
 	ErrRoleIsExist = types.Error{
+		Code:    errs.UserRoleExists,
 		Message: "This role already exists for the user",
 		Field:   "role_id",
 		Tag:     "user-role",
 	}
 	ErrRoleIsNotExist = types.Error{
+		Code:    errs.UserRoleNotAssigned,
 		Message: "This role does not exist for the user",
 		Field:   "role_id",
 		Tag:     "user-role",
 	}
+	ErrCantRemoveDefaultRole = types.Error{
+		Code:    errs.UserRoleDefaultRole,
+		Message: "Default role can`t be removed",
+		Field:   "role_id",
+		Tag:     "user-role",
+	}
+	ErrUserRoleNotFound = types.Error{
+		Code:    errs.UserRoleNotFound,
+		Message: "Role request not found",
+		Field:   "id",
+		Tag:     "user-role",
+	}
+	ErrUserRoleAlreadyDecided = types.Error{
+		Code:    errs.UserRoleAlreadyDecided,
+		Message: "Role request has already been decided",
+		Field:   "status",
+		Tag:     "user-role",
+	}
+	ErrRejectReasonRequired = types.Error{
+		Code:    errs.UserRoleReasonRequired,
+		Message: "Reason is required to reject a role request",
+		Field:   "reason",
+		Tag:     "user-role",
+	}
+	ErrCantDecideOwnRole = types.Error{
+		Code:    errs.UserRoleCantDecideOwn,
+		Message: "You can`t grant, take or decide your own roles",
+		Field:   "id",
+		Tag:     "user-role",
+	}
 )
@@ -0,0 +1,66 @@
+package userRoleHandler
+
+import (
+	"health/models"
+	"health/routes/client/userRole"
+	"health/services/openapi"
+	"health/shared/errs"
+	"net/http"
+)
+
+const docsTag = "user-role"
+
+// Endpoints — описание роутов из RegisterHTTPEndpoints для OpenAPI спецификации
+func (m *Module) Endpoints() []openapi.Endpoint {
+	approve := string(models.PermissionUserRoleApprove)
+	manageUsers := string(models.PermissionUserManage)
+
+	addErrors := []errs.Code{errs.AuthUserNotFound, errs.UserRoleRoleNotFound, errs.UserRoleExists}
+	removeErrors := []errs.Code{errs.AuthUserNotFound, errs.UserRoleRoleNotFound, errs.UserRoleDefaultRole, errs.UserRoleNotAssigned}
+	decideErrors := []errs.Code{errs.UserRoleNotFound, errs.UserRoleAlreadyDecided, errs.UserRoleCantDecideOwn}
+
+	return []openapi.Endpoint{
+		{
+			Method: http.MethodPost, Path: "/api/user-role/v1/add", Tag: docsTag, Summary: "Request a role, it waits for approval",
+			Auth:   true,
+			Body:   userRole.RoleInput{},
+			Errors: addErrors,
+		},
+		{
+			Method: http.MethodPost, Path: "/api/user-role/v1/remove", Tag: docsTag, Summary: "Drop one of own roles",
+			Auth:   true,
+			Body:   userRole.RoleInput{},
+			Errors: removeErrors,
+		},
+		{
+			Method: http.MethodPost, Path: "/api/user-role/v1/admin/add", Tag: docsTag, Summary: "Grant a role to a user",
+			Auth: true, Permission: manageUsers,
+			Body:   userRole.AdminRoleInput{},
+			Errors: append(addErrors, errs.UserRoleCantDecideOwn, errs.UserRoleAlreadyDecided),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/user-role/v1/admin/remove", Tag: docsTag, Summary: "Take a role from a user",
+			Auth: true, Permission: manageUsers,
+			Body:   userRole.AdminRoleInput{},
+			Errors: append(removeErrors, errs.UserRoleCantDecideOwn),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/user-role/v1/pending", Tag: docsTag, Summary: "Role requests waiting for a decision",
+			Auth: true, Permission: approve,
+			Query: userRole.PendingInput{},
+			Data:  PendingResponse{},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/user-role/v1/pending/:id/approve", Tag: docsTag, Summary: "Approve a role request",
+			Auth: true, Permission: approve,
+			Body:   userRole.DecisionInput{},
+			Errors: decideErrors,
+		},
+		{
+			Method: http.MethodPost, Path: "/api/user-role/v1/pending/:id/reject", Tag: docsTag, Summary: "Reject a role request with a reason",
+			Auth: true, Permission: approve,
+			Body:   userRole.DecisionInput{},
+			Errors: append(decideErrors, errs.UserRoleReasonRequired),
+		},
+	}
+}
@@ -1,9 +1,11 @@
 package userRoleHandler
 
 import (
+	"context"
 	"health/models"
 	"health/routes/client/auth"
 	"health/routes/client/userRole"
+	"health/services/validation"
 	"health/shared/types"
 	"net/http"
 
@@ -29,32 +31,12 @@ func (h *Handler) AddRole(c *gin.Context) {
 		inp.Email = user.(*models.User).Email
 	}
 
-	if err := c.BindJSON(inp); err != nil {
-		c.JSON(http.StatusBadRequest, types.BadResponse{
-			Code: http.StatusBadRequest,
-			Error: &types.Error{
-				Message: err.Error(),
-				Field:   "input data",
-				Tag:     "user-role",
-			},
-		})
-		return
-	}
-
-	if err := userRole.ValidateRoleInput(inp); err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
-
+	if !validation.BindJSON(c, inp, "user-role") {
 		return
 	}
 
 	if err := h.useCase.AddRole(c.Request.Context(), inp); err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
+		c.Error(err)
 		return
 	}
 
@@ -70,34 +52,96 @@ func (h *Handler) RemoveRole(c *gin.Context) {
 		inp.Email = user.(*models.User).Email
 	}
 
-	if err := c.BindJSON(inp); err != nil {
-		c.JSON(http.StatusBadRequest, types.BadResponse{
-			Code: http.StatusBadRequest,
-			Error: &types.Error{
-				Message: err.Error(),
-				Field:   "input data",
-				Tag:     "user-role",
-			},
-		})
+	if !validation.BindJSON(c, inp, "user-role") {
 		return
 	}
 
-	if err := userRole.ValidateRoleInput(inp); err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
+	if err := h.useCase.RemoveRole(c.Request.Context(), inp); err != nil {
+		c.Error(err)
+		return
+	}
 
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) AdminAddRole(c *gin.Context) {
+	h.adminManageRole(c, h.useCase.AdminAddRole)
+}
+
+func (h *Handler) AdminRemoveRole(c *gin.Context) {
+	h.adminManageRole(c, h.useCase.AdminRemoveRole)
+}
+
+func (h *Handler) adminManageRole(c *gin.Context, manage func(ctx context.Context, inp *userRole.AdminRoleInput) *types.Error) {
+	inp := new(userRole.AdminRoleInput)
+
+	if !validation.BindJSON(c, inp, "user-role") {
 		return
 	}
 
-	if err := h.useCase.RemoveRole(c.Request.Context(), inp); err != nil {
-		c.JSON(http.StatusNotAcceptable, types.BadResponse{
-			Code:  http.StatusNotAcceptable,
-			Error: err,
-		})
+	// c токена вытаскиваем
+	if user, exist := c.Get(auth.CtxUserKey); exist {
+		inp.AdminID = user.(*models.User).ID
+	}
+
+	if err := manage(c.Request.Context(), inp); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.Status(http.StatusOK)
-}
\ No newline at end of file
+}
+
+func (h *Handler) GetPending(c *gin.Context) {
+	inp := new(userRole.PendingInput)
+
+	if !validation.BindQuery(c, inp, "user-role") {
+		return
+	}
+
+	list, err := h.useCase.GetPending(c.Request.Context(), inp)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.GoodResponse{
+		Code: http.StatusOK,
+		Data: PendingResponse{
+			Items: list.Items,
+			Total: list.Total,
+			Page:  list.Page,
+			Limit: list.Limit,
+		},
+	})
+}
+
+func (h *Handler) Approve(c *gin.Context) {
+	h.decide(c, h.useCase.Approve)
+}
+
+func (h *Handler) Reject(c *gin.Context) {
+	h.decide(c, h.useCase.Reject)
+}
+
+func (h *Handler) decide(c *gin.Context, decide func(ctx context.Context, inp *userRole.DecisionInput) *types.Error) {
+	inp := new(userRole.DecisionInput)
+
+	if !validation.BindJSON(c, inp, "user-role") {
+		return
+	}
+
+	inp.ID = c.Param("id")
+
+	// c токена вытаскиваем
+	if user, exist := c.Get(auth.CtxUserKey); exist {
+		inp.AdminID = user.(*models.User).ID
+	}
+
+	if err := decide(c.Request.Context(), inp); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -1,35 +1,45 @@
 package userRoleHandler
 
 import (
-	authRepository "health/routes/client/auth/repository"
-	roleRepository "health/routes/client/role/repository"
-	"health/routes/client/userRole/repository"
-	"health/routes/client/userRole/usecase"
+	"health/models"
+	roleHandler "health/routes/client/role/handler"
+	"health/routes/client/userRole"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func RegisterHTTPEndpoints(router *gin.RouterGroup, authMiddleware gin.HandlerFunc, db *mongo.Database) {
-	// Создаем repository, все взаимодействия с db в ней
-	repo := repository.NewRepository(db)
-	roleRepository := roleRepository.NewRepository(db)
-	authRepository := authRepository.NewRepository(db)
+type Module struct {
+	handler        *Handler
+	authMiddleware gin.HandlerFunc
+}
+
+func NewModule(uc userRole.UseCase, authMiddleware gin.HandlerFunc) *Module {
+	return &Module{
+		handler:        NewHandler(uc),
+		authMiddleware: authMiddleware,
+	}
+}
 
-	// Создаем usecase, вся бизнес-логика в нем
-	uc := usecase.NewUseCase(
-		repo,
-		roleRepository,
-		authRepository,
-	)
+func (m *Module) RegisterHTTPEndpoints(router *gin.RouterGroup) {
+	h, authMiddleware := m.handler, m.authMiddleware
 
-	// Create the handler
-	h := NewHandler(uc)
+	// Create the middleware instance
+	canApprove := roleHandler.RequirePermission(models.PermissionUserRoleApprove)
+	canManageUsers := roleHandler.RequirePermission(models.PermissionUserManage)
 
 	// Create the endpoints
-	endpoints := router.Group("/user-role/v1")
+	endpoints := router.Group("/api/user-role/v1")
 	{
 		endpoints.POST("/add", authMiddleware, h.AddRole)
 		endpoints.POST("/remove", authMiddleware, h.RemoveRole)
+
+		// * роли другого юзера меняет только админ, роль выдается сразу одобренной
+		endpoints.POST("/admin/add", authMiddleware, canManageUsers, h.AdminAddRole)
+		endpoints.POST("/admin/remove", authMiddleware, canManageUsers, h.AdminRemoveRole)
+
+		// * заявки на роли разбирает только юзер с правом user-role:approve
+		endpoints.GET("/pending", authMiddleware, canApprove, h.GetPending)
+		endpoints.POST("/pending/:id/approve", authMiddleware, canApprove, h.Approve)
+		endpoints.POST("/pending/:id/reject", authMiddleware, canApprove, h.Reject)
 	}
 }
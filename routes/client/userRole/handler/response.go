@@ -0,0 +1,11 @@
+package userRoleHandler
+
+import "health/routes/client/userRole"
+
+// PendingResponse — страница заявок на роли
+type PendingResponse struct {
+	Items []*userRole.PendingUserRole `json:"items"`
+	Total int64                       `json:"total"`
+	Page  int64                       `json:"page"`
+	Limit int64                       `json:"limit"`
+}
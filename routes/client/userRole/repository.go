@@ -10,4 +10,19 @@ type Repository interface {
 	GetUserRoleByID(ctx context.Context, id string) (*models.UserRole, error)
 	GetUserRoleByIDs(ctx context.Context, ids []string) ([]*models.UserRole, error)
 	DeleteUserRoleByID(ctx context.Context, id string) error
+	DeleteUserRolesByUserID(ctx context.Context, userID string) error
+	// GetUserRoles возвращает страницу записей по фильтру и общее их число
+	GetUserRoles(ctx context.Context, filter *ListFilter) ([]*models.UserRole, int64, error)
+	CountUserRolesByRoleID(ctx context.Context, roleID string) (int64, error)
+	// DecideUserRole записывает решение по заявке, false — если она уже не в ожидании
+	DecideUserRole(ctx context.Context, userRole *models.UserRole) (bool, error)
+}
+
+type ListFilter struct {
+	Status models.UserRoleStatus
+	RoleID string
+	UserID string
+
+	Skip  int64
+	Limit int64
 }
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"health/models"
+	"health/routes/client/userRole"
+	"health/shared/errs"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryRepository — юзер-роли в памяти процесса, ошибки те же, что у монги
+type MemoryRepository struct {
+	mu        sync.RWMutex
+	userRoles map[string]*models.UserRole
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		userRoles: make(map[string]*models.UserRole),
+	}
+}
+
+func (r *MemoryRepository) CreateUserRole(ctx context.Context, userRole *models.UserRole) error {
+	if _, err := primitive.ObjectIDFromHex(userRole.UserID); err != nil {
+		return err
+	}
+	if _, err := primitive.ObjectIDFromHex(userRole.RoleID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	userRole.CreatedAt = time.Now()
+	userRole.UpdatedAt = time.Now()
+
+	// * id может быть сгенерирован заранее, он уже записан в user.userRoleIds
+	if _, err := primitive.ObjectIDFromHex(userRole.ID); err != nil {
+		userRole.ID = primitive.NewObjectID().Hex()
+	}
+
+	stored := *userRole
+	r.userRoles[userRole.ID] = &stored
+
+	return nil
+}
+
+func (r *MemoryRepository) GetUserRoleByID(ctx context.Context, id string) (*models.UserRole, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.userRoles[id]
+	if !ok {
+		return nil, errs.ErrNotFound
+	}
+
+	found := *stored
+
+	return &found, nil
+}
+
+func (r *MemoryRepository) GetUserRoleByIDs(ctx context.Context, ids []string) ([]*models.UserRole, error) {
+	for _, id := range ids {
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var userRoles []*models.UserRole
+	for _, id := range ids {
+		if stored, ok := r.userRoles[id]; ok {
+			found := *stored
+			userRoles = append(userRoles, &found)
+		}
+	}
+
+	return userRoles, nil
+}
+
+func (r *MemoryRepository) DeleteUserRoleByID(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.userRoles, id)
+
+	return nil
+}
+
+func (r *MemoryRepository) DeleteUserRolesByUserID(ctx context.Context, userID string) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, stored := range r.userRoles {
+		if stored.UserID == userID {
+			delete(r.userRoles, id)
+		}
+	}
+
+	return nil
+}
+
+func (r *MemoryRepository) GetUserRoles(ctx context.Context, inp *userRole.ListFilter) ([]*models.UserRole, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := []*models.UserRole{}
+	for _, stored := range r.userRoles {
+		if inp.Status != "" && stored.Status != inp.Status {
+			continue
+		}
+		if inp.RoleID != "" && stored.RoleID != inp.RoleID {
+			continue
+		}
+		if inp.UserID != "" && stored.UserID != inp.UserID {
+			continue
+		}
+
+		matched = append(matched, stored)
+	}
+
+	// * Старые заявки первыми, чтобы их разбирали по очереди
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.Before(matched[j].CreatedAt)
+		}
+
+		return matched[i].ID < matched[j].ID
+	})
+
+	total := int64(len(matched))
+
+	userRoles := []*models.UserRole{}
+	for i := inp.Skip; i < total; i++ {
+		if inp.Limit > 0 && int64(len(userRoles)) >= inp.Limit {
+			break
+		}
+
+		found := *matched[i]
+		userRoles = append(userRoles, &found)
+	}
+
+	return userRoles, total, nil
+}
+
+func (r *MemoryRepository) CountUserRolesByRoleID(ctx context.Context, roleID string) (int64, error) {
+	if _, err := primitive.ObjectIDFromHex(roleID); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, stored := range r.userRoles {
+		if stored.RoleID == roleID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *MemoryRepository) DecideUserRole(ctx context.Context, userRole *models.UserRole) (bool, error) {
+	if _, err := primitive.ObjectIDFromHex(userRole.ID); err != nil {
+		return false, err
+	}
+	if _, err := primitive.ObjectIDFromHex(userRole.DecidedBy); err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// * Решение принимается только по заявке в ожидании, два админа не перезапишут друг друга
+	stored, ok := r.userRoles[userRole.ID]
+	if !ok || stored.Status != models.UserRoleStatusPending {
+		return false, nil
+	}
+
+	userRole.UpdatedAt = time.Now()
+
+	stored.Status = userRole.Status
+	stored.DecidedBy = userRole.DecidedBy
+	stored.DecidedAt = userRole.DecidedAt
+	stored.Reason = userRole.Reason
+	stored.UpdatedAt = userRole.UpdatedAt
+
+	return true, nil
+}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"health/models"
+	"health/routes/client/userRole"
+	"health/services/transaction"
+	"health/shared/utils"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const userRoleColumns = `id, user_id, role_id, status, decided_by, decided_at, reason, created_at, updated_at`
+
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresRepository) CreateUserRole(ctx context.Context, userRole *models.UserRole) error {
+	if _, err := primitive.ObjectIDFromHex(userRole.UserID); err != nil {
+		return err
+	}
+	if _, err := primitive.ObjectIDFromHex(userRole.RoleID); err != nil {
+		return err
+	}
+
+	userRole.CreatedAt = time.Now()
+	userRole.UpdatedAt = time.Now()
+
+	// * id может быть сгенерирован заранее, он уже записан в user.userRoleIds
+	if _, err := primitive.ObjectIDFromHex(userRole.ID); err != nil {
+		userRole.ID = primitive.NewObjectID().Hex()
+	}
+
+	_, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO user_roles (`+userRoleColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		userRole.ID, userRole.UserID, userRole.RoleID, string(userRole.Status),
+		nullString(userRole.DecidedBy), userRole.DecidedAt, userRole.Reason,
+		userRole.CreatedAt, userRole.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *PostgresRepository) GetUserRoleByID(ctx context.Context, id string) (*models.UserRole, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+
+	row := transaction.SQLExecutor(ctx, r.db).QueryRowContext(ctx,
+		`SELECT `+userRoleColumns+` FROM user_roles WHERE id = $1`,
+		id,
+	)
+
+	return scanUserRole(row)
+}
+
+func (r *PostgresRepository) GetUserRoleByIDs(ctx context.Context, ids []string) ([]*models.UserRole, error) {
+	for _, id := range ids {
+		if _, err := primitive.ObjectIDFromHex(id); err != nil {
+			return nil, err
+		}
+	}
+
+	if ids == nil {
+		ids = []string{}
+	}
+
+	rows, err := transaction.SQLExecutor(ctx, r.db).QueryContext(ctx,
+		`SELECT `+userRoleColumns+` FROM user_roles WHERE id = ANY($1) ORDER BY id`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userRoles []*models.UserRole
+	for rows.Next() {
+		userRole, err := scanUserRole(rows)
+		if err != nil {
+			return userRoles, err
+		}
+		userRoles = append(userRoles, userRole)
+	}
+
+	if err := rows.Err(); err != nil {
+		return userRoles, err
+	}
+
+	return userRoles, nil
+}
+
+func (r *PostgresRepository) DeleteUserRoleByID(ctx context.Context, id string) error {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return err
+	}
+
+	_, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx, `DELETE FROM user_roles WHERE id = $1`, id)
+
+	return err
+}
+
+func (r *PostgresRepository) DeleteUserRolesByUserID(ctx context.Context, userID string) error {
+	if _, err := primitive.ObjectIDFromHex(userID); err != nil {
+		return err
+	}
+
+	_, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx, `DELETE FROM user_roles WHERE user_id = $1`, userID)
+
+	return err
+}
+
+func (r *PostgresRepository) GetUserRoles(ctx context.Context, inp *userRole.ListFilter) ([]*models.UserRole, int64, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if inp.Status != "" {
+		addCondition("status = $%d", string(inp.Status))
+	}
+	if inp.RoleID != "" {
+		addCondition("role_id = $%d", inp.RoleID)
+	}
+	if inp.UserID != "" {
+		addCondition("user_id = $%d", inp.UserID)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	executor := transaction.SQLExecutor(ctx, r.db)
+
+	var total int64
+	if err := executor.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_roles`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// * Старые заявки первыми, чтобы их разбирали по очереди
+	query := fmt.Sprintf(`SELECT %s FROM user_roles%s ORDER BY created_at, id OFFSET %d`, userRoleColumns, where, inp.Skip)
+	if inp.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", inp.Limit)
+	}
+
+	rows, err := executor.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	userRoles := []*models.UserRole{}
+	for rows.Next() {
+		userRole, err := scanUserRole(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		userRoles = append(userRoles, userRole)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return userRoles, total, nil
+}
+
+func (r *PostgresRepository) CountUserRolesByRoleID(ctx context.Context, roleID string) (int64, error) {
+	if _, err := primitive.ObjectIDFromHex(roleID); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err := transaction.SQLExecutor(ctx, r.db).QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM user_roles WHERE role_id = $1`,
+		roleID,
+	).Scan(&count)
+
+	return count, err
+}
+
+func (r *PostgresRepository) DecideUserRole(ctx context.Context, userRole *models.UserRole) (bool, error) {
+	if _, err := primitive.ObjectIDFromHex(userRole.ID); err != nil {
+		return false, err
+	}
+	if _, err := primitive.ObjectIDFromHex(userRole.DecidedBy); err != nil {
+		return false, err
+	}
+
+	userRole.UpdatedAt = time.Now()
+
+	// * Решение принимается только по заявке в ожидании, два админа не перезапишут друг друга
+	res, err := transaction.SQLExecutor(ctx, r.db).ExecContext(ctx,
+		`UPDATE user_roles SET status = $3, decided_by = $4, decided_at = $5, reason = $6, updated_at = $7
+		WHERE id = $1 AND status = $2`,
+		userRole.ID, string(models.UserRoleStatusPending), string(userRole.Status),
+		userRole.DecidedBy, userRole.DecidedAt, userRole.Reason, userRole.UpdatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUserRole(row rowScanner) (*models.UserRole, error) {
+	userRole := new(models.UserRole)
+
+	var status string
+	var decidedBy sql.NullString
+	err := row.Scan(
+		&userRole.ID, &userRole.UserID, &userRole.RoleID, &status,
+		&decidedBy, &userRole.DecidedAt, &userRole.Reason,
+		&userRole.CreatedAt, &userRole.UpdatedAt,
+	)
+	if err != nil {
+		return nil, utils.SQLNotFound(err)
+	}
+
+	userRole.Status = models.UserRoleStatus(status)
+	// * DecidedBy пустой, пока заявку не рассмотрели
+	userRole.DecidedBy = decidedBy.String
+
+	return userRole, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
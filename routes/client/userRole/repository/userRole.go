@@ -3,11 +3,14 @@ package repository
 import (
 	"context"
 	"health/models"
+	"health/routes/client/userRole"
+	"health/shared/utils"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Repository struct {
@@ -25,6 +28,11 @@ func (r *Repository) CreateUserRole(ctx context.Context, userRole *models.UserRo
 	userRole.UpdatedAt = time.Now()
 
 	model := mapToMongoSchema(userRole)
+	// * id может быть сгенерирован заранее, он уже записан в user.userRoleIds
+	if oid, err := primitive.ObjectIDFromHex(userRole.ID); err == nil {
+		model.ID = oid
+	}
+
 	res, err := r.InsertOne(ctx, model)
 	if err != nil {
 		return err
@@ -50,7 +58,7 @@ func (r *Repository) GetUserRoleByID(ctx context.Context, id string) (*models.Us
 	}
 	err = r.FindOne(ctx, filter).Decode(userRole)
 	if err != nil {
-		return nil, err
+		return nil, utils.MongoNotFound(err)
 	}
 
 	return mapToDomainModel(userRole), nil
@@ -111,6 +119,120 @@ func (r *Repository) DeleteUserRoleByID(ctx context.Context, id string) error {
 	return nil
 }
 
+func (r *Repository) DeleteUserRolesByUserID(ctx context.Context, userID string) error {
+	oid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DeleteMany(ctx, bson.M{"userId": oid})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Repository) GetUserRoles(ctx context.Context, inp *userRole.ListFilter) ([]*models.UserRole, int64, error) {
+	userRoles := []*models.UserRole{}
+
+	filter := bson.M{}
+	if inp.Status != "" {
+		filter["status"] = inp.Status
+	}
+	if inp.RoleID != "" {
+		oid, err := primitive.ObjectIDFromHex(inp.RoleID)
+		if err != nil {
+			return userRoles, 0, nil
+		}
+		filter["roleId"] = oid
+	}
+	if inp.UserID != "" {
+		oid, err := primitive.ObjectIDFromHex(inp.UserID)
+		if err != nil {
+			return userRoles, 0, nil
+		}
+		filter["userId"] = oid
+	}
+
+	total, err := r.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// * Старые заявки первыми, чтобы их разбирали по очереди
+	opts := options.Find().
+		SetSort(bson.M{"created_at": 1}).
+		SetSkip(inp.Skip).
+		SetLimit(inp.Limit)
+
+	cur, err := r.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var userRoleDBSchema models.UserRoleDBSchema
+		if err := cur.Decode(&userRoleDBSchema); err != nil {
+			return nil, 0, err
+		}
+
+		userRoles = append(userRoles, mapToDomainModel(&userRoleDBSchema))
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return userRoles, total, nil
+}
+
+func (r *Repository) CountUserRolesByRoleID(ctx context.Context, roleID string) (int64, error) {
+	oid, err := primitive.ObjectIDFromHex(roleID)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.CountDocuments(ctx, bson.M{"roleId": oid})
+}
+
+func (r *Repository) DecideUserRole(ctx context.Context, userRole *models.UserRole) (bool, error) {
+	oid, err := primitive.ObjectIDFromHex(userRole.ID)
+	if err != nil {
+		return false, err
+	}
+
+	decidedBy, err := primitive.ObjectIDFromHex(userRole.DecidedBy)
+	if err != nil {
+		return false, err
+	}
+
+	userRole.UpdatedAt = time.Now()
+
+	// * Решение принимается только по заявке в ожидании, два админа не перезапишут друг друга
+	filter := bson.M{
+		"_id":    oid,
+		"status": models.UserRoleStatusPending,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     userRole.Status,
+			"decidedBy":  decidedBy,
+			"decided_at": userRole.DecidedAt,
+			"reason":     userRole.Reason,
+			"updated_at": userRole.UpdatedAt,
+		},
+	}
+
+	res, err := r.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return res.ModifiedCount == 1, nil
+}
+
 func mapToMongoSchema(i *models.UserRole) *models.UserRoleDBSchema {
 	UserOid, err := primitive.ObjectIDFromHex(i.UserID)
 	if err != nil {
@@ -122,17 +244,29 @@ func mapToMongoSchema(i *models.UserRole) *models.UserRoleDBSchema {
 		return nil
 	}
 
+	// * DecidedBy пустой, пока заявку не рассмотрели
+	DecidedByOid, _ := primitive.ObjectIDFromHex(i.DecidedBy)
+
 	return &models.UserRoleDBSchema{
 		UserID: UserOid,
 		RoleID: RoleOid,
 		Status: i.Status,
 
+		DecidedBy: DecidedByOid,
+		DecidedAt: i.DecidedAt,
+		Reason:    i.Reason,
+
 		CreatedAt: i.CreatedAt,
 		UpdatedAt: i.UpdatedAt,
 	}
 }
 
 func mapToDomainModel(i *models.UserRoleDBSchema) *models.UserRole {
+	decidedBy := ""
+	if !i.DecidedBy.IsZero() {
+		decidedBy = i.DecidedBy.Hex()
+	}
+
 	return &models.UserRole{
 		ID: i.ID.Hex(),
 
@@ -140,6 +274,10 @@ func mapToDomainModel(i *models.UserRoleDBSchema) *models.UserRole {
 		RoleID: i.RoleID.Hex(),
 		Status: i.Status,
 
+		DecidedBy: decidedBy,
+		DecidedAt: i.DecidedAt,
+		Reason:    i.Reason,
+
 		CreatedAt: i.CreatedAt,
 		UpdatedAt: i.UpdatedAt,
 	}
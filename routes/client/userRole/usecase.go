@@ -2,10 +2,41 @@ package userRole
 
 import (
 	"context"
+	"health/models"
 	"health/shared/types"
+	"time"
 )
 
+// PendingUserRole — заявка на роль вместе с юзером и названием роли для админки
+type PendingUserRole struct {
+	ID       string
+	UserID   string
+	Email    string
+	RoleID   string
+	RoleName models.RoleName
+	Status   models.UserRoleStatus
+
+	CreatedAt time.Time
+}
+
+type PendingList struct {
+	Items []*PendingUserRole
+	Total int64
+	Page  int64
+	Limit int64
+}
+
 type UseCase interface {
 	AddRole(ctx context.Context, inp *RoleInput) *types.Error
 	RemoveRole(ctx context.Context, inp *RoleInput) *types.Error
+	// AdminAddRole выдает роль сразу одобренной, AdminRemoveRole снимает любую роль
+	AdminAddRole(ctx context.Context, inp *AdminRoleInput) *types.Error
+	AdminRemoveRole(ctx context.Context, inp *AdminRoleInput) *types.Error
+
+	// CleanupOrphans удаляет записи user.roles, на которые не ссылается ни один юзер
+	CleanupOrphans(ctx context.Context, dryRun bool) (int, error)
+
+	GetPending(ctx context.Context, inp *PendingInput) (*PendingList, *types.Error)
+	Approve(ctx context.Context, inp *DecisionInput) *types.Error
+	Reject(ctx context.Context, inp *DecisionInput) *types.Error
 }
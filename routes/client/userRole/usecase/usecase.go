@@ -3,37 +3,67 @@ package usecase
 import (
 	"context"
 	"health/models"
+	service_email "health/services/email"
+	"health/services/i18n"
+	"time"
 
 	"health/routes/client/auth"
 	"health/routes/client/role"
 	"health/routes/client/userRole"
+	"health/shared/errs"
 	"health/shared/types"
 	"health/shared/utils"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	defaultPendingLimit = 20
+
+	cleanupBatchSize  = 500
+	orphanGracePeriod = 10 * time.Minute
+)
+
 type UseCase struct {
 	repo     userRole.Repository
 	roleRepo role.Repository
 	userRepo auth.Repository
+	mailer   *service_email.Mailer
+	// * Кеш юзеров с ролями живет в auth
+	auth auth.UseCase
 }
 
-func NewUseCase(repo userRole.Repository, roleRepo role.Repository, userRepo auth.Repository) *UseCase {
+func NewUseCase(repo userRole.Repository, roleRepo role.Repository, userRepo auth.Repository, mailer *service_email.Mailer, authUseCase auth.UseCase) *UseCase {
 	return &UseCase{
 		repo:     repo,
 		roleRepo: roleRepo,
 		userRepo: userRepo,
+		mailer:   mailer,
+		auth:     authUseCase,
 	}
 }
 
 func (a *UseCase) AddRole(ctx context.Context, inp *userRole.RoleInput) *types.Error {
-	user, err := a.userRepo.GetUserById(ctx, inp.ID)
+	// * Заявка юзера ждет решения админа
+	return a.addRole(ctx, inp.ID, inp.RoleID, models.UserRoleStatusPending, "")
+}
+
+func (a *UseCase) AdminAddRole(ctx context.Context, inp *userRole.AdminRoleInput) *types.Error {
+	// * Свои роли админ не меняет: с одним user:manage он выдал бы себе admin, а с ним role:manage и user-role:approve
+	if inp.UserID == inp.AdminID {
+		return &userRole.ErrCantDecideOwnRole
+	}
+
+	return a.addRole(ctx, inp.UserID, inp.RoleID, models.UserRoleStatusApproved, inp.AdminID)
+}
+
+func (a *UseCase) addRole(ctx context.Context, userID string, roleID string, status models.UserRoleStatus, decidedBy string) *types.Error {
+	user, err := a.userRepo.GetUserById(ctx, userID)
 	if err != nil {
 		return &auth.ErrUserNotFound
 	}
 
-	roleEntity, err := a.roleRepo.GetRoleByID(ctx, inp.RoleID)
+	roleEntity, err := a.roleRepo.GetRoleByID(ctx, roleID)
 	if err != nil {
 		return &userRole.ErrCantFindRole
 	}
@@ -42,93 +72,391 @@ func (a *UseCase) AddRole(ctx context.Context, inp *userRole.RoleInput) *types.E
 	userRoleEntities, err := a.repo.GetUserRoleByIDs(ctx, user.UserRoleIDs)
 	if err != nil {
 		return &types.Error{
-			Message: err.Error(),
-			Field:   "user-role-ids",
-			Tag:     "user-role",
+			Code:  errs.Internal,
+			Field: "user-role-ids",
+			Tag:   "user-role",
+			Cause: err,
 		}
 	}
 	for _, userRoleEntity := range userRoleEntities {
-		if userRoleEntity.RoleID == roleEntity.ID {
-			return &userRole.ErrRoleIsExist
+		if userRoleEntity.RoleID != roleEntity.ID {
+			continue
+		}
+
+		// * Админ выдает роль, на которую уже есть заявка: одобряем заявку, а не заводим вторую запись
+		if userRoleEntity.Status == models.UserRoleStatusPending && status == models.UserRoleStatusApproved {
+			return a.approvePending(ctx, user, userRoleEntity, decidedBy)
 		}
+
+		return &userRole.ErrRoleIsExist
 	}
 
 	userRoleID := primitive.NewObjectID()
 
 	// Создаем юзер.роль
-	userRole := models.UserRole{
+	userRoleEntity := models.UserRole{
 		ID:     userRoleID.Hex(),
 		UserID: user.ID,
 		RoleID: roleEntity.ID,
-		Status: models.UserRoleStatusPending,
+		Status: status,
+	}
+	if decidedBy != "" {
+		userRoleEntity.DecidedBy = decidedBy
+		userRoleEntity.DecidedAt = time.Now()
 	}
-	err = a.repo.CreateUserRole(ctx, &userRole)
+
+	err = a.repo.CreateUserRole(ctx, &userRoleEntity)
 	if err != nil {
 		return &types.Error{
-			Message: err.Error(),
-			Field:   "create-user-role",
-			Tag:     "user-role",
+			Code:  errs.Internal,
+			Field: "create-user-role",
+			Tag:   "user-role",
+			Cause: err,
 		}
 	}
 
 	user.UserRoleIDs = append(user.UserRoleIDs, userRoleID.Hex())
+	user.RolesVersion++
+	if err := a.userRepo.UpdateUser(ctx, user); err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "update-user",
+			Tag:   "user-role",
+			Cause: err,
+		}
+	}
+	a.auth.ForgetUser(user.ID)
+
+	return nil
+}
+
+// approvePending одобряет существующую заявку юзера от имени админа
+func (a *UseCase) approvePending(ctx context.Context, user *models.User, userRoleEntity *models.UserRole, decidedBy string) *types.Error {
+	userRoleEntity.Status = models.UserRoleStatusApproved
+	userRoleEntity.DecidedBy = decidedBy
+	userRoleEntity.DecidedAt = time.Now()
+
+	isDecided, err := a.repo.DecideUserRole(ctx, userRoleEntity)
+	if err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "decide",
+			Tag:   "user-role",
+			Cause: err,
+		}
+	}
+	if !isDecided {
+		return &userRole.ErrUserRoleAlreadyDecided
+	}
+
+	user.RolesVersion++
 	if err := a.userRepo.UpdateUser(ctx, user); err != nil {
 		return &types.Error{
-			Message: err.Error(),
-			Field:   "update-user",
-			Tag:     "user-role",
+			Code:  errs.Internal,
+			Field: "update-user",
+			Tag:   "user-role",
+			Cause: err,
 		}
 	}
+	a.auth.ForgetUser(user.ID)
 
 	return nil
 }
 
 func (a *UseCase) RemoveRole(ctx context.Context, inp *userRole.RoleInput) *types.Error {
-	user, err := a.userRepo.GetUserById(ctx, inp.ID)
+	return a.removeRole(ctx, inp.ID, inp.RoleID, false)
+}
+
+func (a *UseCase) AdminRemoveRole(ctx context.Context, inp *userRole.AdminRoleInput) *types.Error {
+	if inp.UserID == inp.AdminID {
+		return &userRole.ErrCantDecideOwnRole
+	}
+
+	return a.removeRole(ctx, inp.UserID, inp.RoleID, true)
+}
+
+// removeRole убирает роль из юзера и удаляет сами записи user.roles.
+// Юзер обновляется первым: если удаление не пройдет, останется сирота, которую уберет CleanupOrphans.
+func (a *UseCase) removeRole(ctx context.Context, userID string, roleID string, isAdmin bool) *types.Error {
+	user, err := a.userRepo.GetUserById(ctx, userID)
 	if err != nil {
 		return &auth.ErrUserNotFound
 	}
 
-	roleEntity, err := a.roleRepo.GetRoleByID(ctx, inp.RoleID)
+	roleEntity, err := a.roleRepo.GetRoleByID(ctx, roleID)
 	if err != nil {
 		return &userRole.ErrCantFindRole
 	}
 
+	// * Базовую роль юзер сам снять не может, без нее он теряет доступ к профилю
+	if roleEntity.IsDefault && !isAdmin {
+		return &userRole.ErrCantRemoveDefaultRole
+	}
+
 	userRoleEntities, err := a.repo.GetUserRoleByIDs(ctx, user.UserRoleIDs)
 	if err != nil {
 		return &types.Error{
-			Message: err.Error(),
-			Field:   "remove-role",
-			Tag:     "user-role",
+			Code:  errs.Internal,
+			Field: "remove-role",
+			Tag:   "user-role",
+			Cause: err,
 		}
 	}
 
-	// * Существует ли уже роль?
-	isExist := false
-
+	var removedIDs []string
 	for _, userRoleEntity := range userRoleEntities {
 		if userRoleEntity.RoleID == roleEntity.ID {
-			isExist = true
+			removedIDs = append(removedIDs, userRoleEntity.ID)
+		}
+	}
 
-			for index, userRoleID := range user.UserRoleIDs {
-				if userRoleEntity.ID == userRoleID {
-					user.UserRoleIDs = utils.RemoveElementByIndex(user.UserRoleIDs, index)
-				}
+	if len(removedIDs) == 0 {
+		return &userRole.ErrRoleIsNotExist
+	}
+
+	userRoleIDs := make([]string, 0, len(user.UserRoleIDs))
+	for _, id := range user.UserRoleIDs {
+		if index, _ := utils.Find(removedIDs, func(removedID string) bool { return removedID == id }); index == -1 {
+			userRoleIDs = append(userRoleIDs, id)
+		}
+	}
+
+	user.UserRoleIDs = userRoleIDs
+	user.RolesVersion++
+	if err := a.userRepo.UpdateUser(ctx, user); err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "remove-role",
+			Tag:   "user-role",
+			Cause: err,
+		}
+	}
+	a.auth.ForgetUser(user.ID)
+
+	for _, id := range removedIDs {
+		if err := a.repo.DeleteUserRoleByID(ctx, id); err != nil {
+			return &types.Error{
+				Code:  errs.Internal,
+				Field: "remove-role",
+				Tag:   "user-role",
+				Cause: err,
 			}
 		}
 	}
 
-	if !isExist {
-		return &userRole.ErrRoleIsNotExist
+	return nil
+}
+
+func (a *UseCase) GetPending(ctx context.Context, inp *userRole.PendingInput) (*userRole.PendingList, *types.Error) {
+	page, limit := inp.Page, inp.Limit
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = defaultPendingLimit
 	}
 
+	userRoles, total, err := a.repo.GetUserRoles(ctx, &userRole.ListFilter{
+		Status: models.UserRoleStatusPending,
+		RoleID: inp.RoleID,
+		UserID: inp.UserID,
+		Skip:   (page - 1) * limit,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "pending",
+			Tag:   "user-role",
+			Cause: err,
+		}
+	}
+
+	roles, err := a.roleRepo.GetRoles(ctx)
+	if err != nil {
+		return nil, &types.Error{
+			Code:  errs.Internal,
+			Field: "pending",
+			Tag:   "user-role",
+			Cause: err,
+		}
+	}
+
+	roleNames := make(map[string]models.RoleName, len(roles))
+	for _, role := range roles {
+		roleNames[role.ID] = role.Name
+	}
+
+	items := make([]*userRole.PendingUserRole, 0, len(userRoles))
+	for _, userRoleEntity := range userRoles {
+		item := &userRole.PendingUserRole{
+			ID:        userRoleEntity.ID,
+			UserID:    userRoleEntity.UserID,
+			RoleID:    userRoleEntity.RoleID,
+			RoleName:  roleNames[userRoleEntity.RoleID],
+			Status:    userRoleEntity.Status,
+			CreatedAt: userRoleEntity.CreatedAt,
+		}
+
+		// * Юзер мог быть удален, заявку все равно показываем
+		if user, err := a.userRepo.GetUserById(ctx, userRoleEntity.UserID); err == nil {
+			item.Email = user.Email
+		}
+
+		items = append(items, item)
+	}
+
+	return &userRole.PendingList{
+		Items: items,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}, nil
+}
+
+func (a *UseCase) Approve(ctx context.Context, inp *userRole.DecisionInput) *types.Error {
+	return a.decide(ctx, inp, models.UserRoleStatusApproved)
+}
+
+func (a *UseCase) Reject(ctx context.Context, inp *userRole.DecisionInput) *types.Error {
+	if inp.Reason == "" {
+		return &userRole.ErrRejectReasonRequired
+	}
+
+	return a.decide(ctx, inp, models.UserRoleStatusCanceled)
+}
+
+type RoleDecisionEmailContent struct {
+	RoleName models.RoleName
+	Approved bool
+	Reason   string
+}
+
+// decide записывает решение по заявке, поднимает RolesVersion юзера и уведомляет его письмом
+func (a *UseCase) decide(ctx context.Context, inp *userRole.DecisionInput, status models.UserRoleStatus) *types.Error {
+	userRoleEntity, err := a.repo.GetUserRoleByID(ctx, inp.ID)
+	if err != nil {
+		return &userRole.ErrUserRoleNotFound
+	}
+
+	if userRoleEntity.UserID == inp.AdminID {
+		return &userRole.ErrCantDecideOwnRole
+	}
+
+	if userRoleEntity.Status != models.UserRoleStatusPending {
+		return &userRole.ErrUserRoleAlreadyDecided
+	}
+
+	userRoleEntity.Status = status
+	userRoleEntity.DecidedBy = inp.AdminID
+	userRoleEntity.DecidedAt = time.Now()
+	userRoleEntity.Reason = inp.Reason
+
+	isDecided, err := a.repo.DecideUserRole(ctx, userRoleEntity)
+	if err != nil {
+		return &types.Error{
+			Code:  errs.Internal,
+			Field: "decide",
+			Tag:   "user-role",
+			Cause: err,
+		}
+	}
+	if !isDecided {
+		return &userRole.ErrUserRoleAlreadyDecided
+	}
+
+	user, err := a.userRepo.GetUserById(ctx, userRoleEntity.UserID)
+	if err != nil {
+		return &auth.ErrUserNotFound
+	}
+
+	// * RolesVersion попадает в новые токены. Старые токены несут версию не новее кешированной,
+	// * поэтому кеш не перечитается сам: убираем юзера из него явно
+	user.RolesVersion++
 	if err := a.userRepo.UpdateUser(ctx, user); err != nil {
 		return &types.Error{
-			Message: err.Error(),
-			Field:   "remove-role",
-			Tag:     "user-role",
+			Code:  errs.Internal,
+			Field: "update-user",
+			Tag:   "user-role",
+			Cause: err,
 		}
 	}
+	a.auth.ForgetUser(user.ID)
+
+	roleEntity, err := a.roleRepo.GetRoleByID(ctx, userRoleEntity.RoleID)
+	if err != nil {
+		return &userRole.ErrCantFindRole
+	}
+
+	// Отправляем письмо. Решение принимает админ, поэтому язык только из профиля юзера
+	locale := i18n.ForUser(user.Locale)
+	emailMessage := service_email.Message{
+		Subject:      i18n.T(locale, "email.role_decision.subject"),
+		To:           []string{user.Email},
+		TemplateName: "RoleDecision",
+		Locale:       locale,
+		Content: RoleDecisionEmailContent{
+			RoleName: roleEntity.Name,
+			Approved: status == models.UserRoleStatusApproved,
+			Reason:   userRoleEntity.Reason,
+		},
+	}
+	a.mailer.Send(&emailMessage)
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (a *UseCase) CleanupOrphans(ctx context.Context, dryRun bool) (int, error) {
+	var orphanIDs []string
+	users := make(map[string]*models.User)
+
+	// * Сначала собираем сирот, удаление во время обхода сдвинуло бы страницы
+	for skip := int64(0); ; skip += cleanupBatchSize {
+		userRoles, _, err := a.repo.GetUserRoles(ctx, &userRole.ListFilter{
+			Skip:  skip,
+			Limit: cleanupBatchSize,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		for _, userRoleEntity := range userRoles {
+			// * Свежая запись может быть еще не привязана к юзеру, см. addRole
+			if time.Since(userRoleEntity.CreatedAt) < orphanGracePeriod {
+				continue
+			}
+
+			user, ok := users[userRoleEntity.UserID]
+			if !ok {
+				// * Юзера нет — все его записи сироты
+				user, _ = a.userRepo.GetUserById(ctx, userRoleEntity.UserID)
+				users[userRoleEntity.UserID] = user
+			}
+
+			if user == nil {
+				orphanIDs = append(orphanIDs, userRoleEntity.ID)
+				continue
+			}
+
+			if index, _ := utils.Find(user.UserRoleIDs, func(id string) bool { return id == userRoleEntity.ID }); index == -1 {
+				orphanIDs = append(orphanIDs, userRoleEntity.ID)
+			}
+		}
+
+		if int64(len(userRoles)) < cleanupBatchSize {
+			break
+		}
+	}
+
+	if dryRun {
+		return len(orphanIDs), nil
+	}
+
+	for _, id := range orphanIDs {
+		if err := a.repo.DeleteUserRoleByID(ctx, id); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(orphanIDs), nil
+}
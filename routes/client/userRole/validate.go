@@ -1,35 +1,31 @@
 package userRole
 
-import (
-	"fmt"
-	"health/shared/types"
-
-	"github.com/go-playground/validator"
-)
-
+// RoleInput — юзер управляет своими ролями, ID и Email берутся только из токена
 type RoleInput struct {
-	ID    string `json:"_id,omitempty"`
-	Email string `json:"email"`
+	ID    string `json:"-"`
+	Email string `json:"-"`
 
 	RoleID string `json:"roleId,omitempty" validate:"required"`
 }
 
-func ValidateRoleInput(inp *RoleInput) *types.Error {
-	validate := validator.New()
-	err := validate.Struct(inp)
+// AdminRoleInput — админ управляет ролями любого юзера
+type AdminRoleInput struct {
+	AdminID string `json:"-"`
 
-	if err != nil {
-		for _, err := range err.(validator.ValidationErrors) {
-			switch err.Tag() {
-			case "required":
-				return &types.Error{
-					Message: fmt.Sprintf("%s is required", err.Field()),
-					Field:   "role-id",
-					Tag:     "user-role",
-				}
-			}
-		}
-	}
+	UserID string `json:"userId" validate:"required"`
+	RoleID string `json:"roleId" validate:"required"`
+}
 
-	return nil
-}
\ No newline at end of file
+type PendingInput struct {
+	Page   int64  `form:"page"   validate:"omitempty,min=1"`
+	Limit  int64  `form:"limit"  validate:"omitempty,min=1,max=100"`
+	RoleID string `form:"roleId"`
+	UserID string `form:"userId"`
+}
+
+type DecisionInput struct {
+	ID      string `json:"-"` // id заявки из пути
+	AdminID string `json:"-"`
+
+	Reason string `json:"reason" validate:"max=500"`
+}
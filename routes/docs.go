@@ -0,0 +1,87 @@
+package routes
+
+import (
+	_ "embed"
+	"net/http"
+
+	"health/services/openapi"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+)
+
+const (
+	specPath = "/openapi.json"
+	docsPath = "/docs/*filepath"
+)
+
+// DocsPaths — роуты самой документации, в спецификацию они не входят
+var DocsPaths = []string{specPath, docsPath}
+
+//go:embed docs/index.html
+var docsIndex []byte
+
+// Spec собирает OpenAPI спецификацию из описаний роутов модулей и роутов этого пакета
+func Spec(modules ...Module) *openapi.Document {
+	endpoints := ownEndpoints()
+	for _, module := range modules {
+		endpoints = append(endpoints, module.Endpoints()...)
+	}
+
+	return openapi.Build(openapi.Info{
+		Title:   "Health API",
+		Version: "1.0.0",
+		Description: "Errors come as BadResponse with a stable `code`, messages are localised by `Accept-Language`. " +
+			"Access token goes to the Authorization header as is, without the Bearer prefix. " +
+			"Services verifying access tokens with `/.well-known/jwks.json` must require the `typ: at+jwt` header and `aud: health-api`: " +
+			"MFA and password reset tokens are signed with the same keys.",
+	}, endpoints)
+}
+
+// CheckSpec вешает роуты модулей на пустой роутер и сверяет их со спецификацией:
+// новый роут без описания в Endpoints или описание удаленного роута — расхождение.
+// documented — число роутов без роутов самой документации.
+func CheckSpec(authMiddleware gin.HandlerFunc, modules ...Module) (problems []string, documented int) {
+	router := gin.New()
+	InitRoutes(router, authMiddleware, modules...)
+
+	var registered []openapi.Route
+	for _, route := range router.Routes() {
+		registered = append(registered, openapi.Route{Method: route.Method, Path: route.Path})
+	}
+
+	return openapi.Diff(Spec(modules...), registered, DocsPaths...), len(registered) - len(DocsPaths)
+}
+
+// ownEndpoints — роуты, которые InitRoutes вешает сам
+func ownEndpoints() []openapi.Endpoint {
+	check := func(path string, summary string) openapi.Endpoint {
+		return openapi.Endpoint{Method: http.MethodGet, Path: path, Tag: "check", Summary: summary, Auth: true, Text: true}
+	}
+
+	return []openapi.Endpoint{
+		{Method: http.MethodGet, Path: "/ping", Tag: "check", Summary: "Liveness probe", Text: true},
+		check("/api/check-user", "Passes with the user role"),
+		check("/api/check-specialist", "Passes with the specialist role"),
+		check("/api/check-minion", "Passes with the minion role"),
+		check("/api/check", "Passes with any valid access token"),
+	}
+}
+
+// registerDocs вешает спецификацию и Swagger UI. Статику Swagger UI отдает swaggo/files,
+// index.html свой — он смотрит на нашу спецификацию вместо petstore.
+func registerDocs(router *gin.Engine, doc *openapi.Document) {
+	router.GET(specPath, func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	})
+
+	assets := http.StripPrefix("/docs", http.FileServer(swaggerFiles.HTTP))
+	router.GET(docsPath, func(c *gin.Context) {
+		switch c.Param("filepath") {
+		case "/", "/index.html":
+			c.Data(http.StatusOK, "text/html; charset=utf-8", docsIndex)
+		default:
+			assets.ServeHTTP(c.Writer, c.Request)
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package routes_test
+
+import (
+	"testing"
+
+	"health/routes"
+	"health/server"
+	"health/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// * Роутер собирается тем же контейнером, что и на проде, только на memory хранилище
+// и с временными ключами: каждый роут должен быть описан в спецификации и наоборот
+func TestSpecMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	viper.Set("auth.keys.dir", t.TempDir())
+	viper.Set("auth.keys.ephemeral", true)
+
+	c := server.NewContainer(storage.NewMemoryStorage(), nil)
+
+	problems, documented := routes.CheckSpec(c.AuthMiddleware, c.Modules()...)
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+
+	if documented == 0 {
+		t.Error("no routes registered")
+	}
+}
@@ -0,0 +1,54 @@
+package routes
+
+import (
+	"errors"
+	"health/services/i18n"
+	"health/shared/errs"
+	"health/shared/types"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler отвечает на ошибку, которую хендлер или middleware положили через c.Error.
+// Статус берется из кода ошибки, поэтому одинаковые ошибки дают одинаковый статус во всех модулях.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+
+		appErr := new(types.Error)
+		if !errors.As(err, &appErr) {
+			appErr = types.ErrInternal.Wrap(err)
+		}
+
+		status := errs.Status(appErr.Code)
+
+		// * Причину внутренних ошибок пишем в лог, клиенту отдаем общий текст
+		if status >= http.StatusInternalServerError {
+			log.Printf("%s %s: %s/%s: %v", c.Request.Method, c.Request.URL.Path, appErr.Tag, appErr.Field, err)
+
+			internal := types.ErrInternal
+			appErr = &internal
+		}
+
+		// * Текст ответа на языке запроса, код ошибки от языка не зависит
+		appErr = i18n.Localize(i18n.FromContext(c.Request.Context()), appErr)
+
+		if appErr.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(appErr.RetryAfter))
+		}
+
+		c.AbortWithStatusJSON(status, types.BadResponse{
+			Code:  status,
+			Error: appErr,
+		})
+	}
+}
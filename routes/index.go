@@ -3,48 +3,48 @@ package routes
 import (
 	"net/http"
 
-	authHandler "health/routes/client/auth/handler"
+	"health/models"
 	roleHandler "health/routes/client/role/handler"
-	userRoleHandler "health/routes/client/userRole/handler"
-	"health/services/email"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func InitRoutes(router *gin.Engine, db *mongo.Database, mailer *email.Mailer) {
+func InitRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc, modules ...Module) {
+	// * Все ошибки, положенные через c.Error, отдаются здесь
+	router.Use(ErrorHandler())
+	// * Язык ответа: Accept-Language, у авторизованного юзера — язык из профиля
+	router.Use(Locale())
+
 	// Пингуем сервер
 	router.GET("/ping", func(c *gin.Context) {
 		c.String(http.StatusOK, "pong")
 	})
 
-	// * AUTH
-	authMiddleware := authHandler.RegisterHTTPEndpoints(router, db, mailer)
+	// * Модули: auth, role, user.role, admin
+	for _, module := range modules {
+		module.RegisterHTTPEndpoints(&router.RouterGroup)
+	}
+
+	// * OpenAPI спецификация и Swagger UI на /docs/
+	registerDocs(router, Spec(modules...))
 
 	// * API endpoints
 	api := router.Group("/api")
 
-	// * ROLE
-	roleMiddlewareUser, roleMiddlewareSpecialist, roleMiddlewareMinion :=
-		roleHandler.RegisterHTTPEndpoints(api, authMiddleware, db)
-
-	// * USER.ROLE
-	userRoleHandler.RegisterHTTPEndpoints(api, authMiddleware, db)
-
 	// * CHECK ROLE MIDDLEWARES
-	api.GET("/check-user", authMiddleware, roleMiddlewareUser, func(c *gin.Context) {
+	api.GET("/check-user", authMiddleware, roleHandler.RequireAnyRole(models.RoleNameUser), func(c *gin.Context) {
 		c.String(http.StatusOK, "check-user")
 	})
 
-	api.GET("/check-specialist", authMiddleware, roleMiddlewareSpecialist, func(c *gin.Context) {
+	api.GET("/check-specialist", authMiddleware, roleHandler.RequireAnyRole(models.RoleNameSpecialist), func(c *gin.Context) {
 		c.String(http.StatusOK, "check-specialist")
 	})
 
-	api.GET("/check-minion", authMiddleware, roleMiddlewareMinion, func(c *gin.Context) {
+	api.GET("/check-minion", authMiddleware, roleHandler.RequireAnyRole(models.RoleNameMinion), func(c *gin.Context) {
 		c.String(http.StatusOK, "check-minion")
 	})
 
 	api.GET("/check", authMiddleware, func(c *gin.Context) {
 		c.String(http.StatusOK, "success")
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"health/services/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale кладет в контекст запроса язык из Accept-Language.
+// Auth middleware потом заменяет его языком из профиля юзера, если юзер его выбрал.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.Match(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"health/services/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Module — HTTP часть модуля. Все зависимости модуль получает готовыми из контейнера в server,
+// сам он только вешает свои роуты с полными путями, например /api/role/v1/list.
+// Endpoints описывает те же роуты для OpenAPI спецификации, расхождения ловит `openapi check`.
+type Module interface {
+	RegisterHTTPEndpoints(router *gin.RouterGroup)
+	Endpoints() []openapi.Endpoint
+}
@@ -13,15 +13,19 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"health/migrations"
 	"health/routes"
 	service_email "health/services/email"
+	"health/services/i18n"
+	"health/services/migration"
+	"health/services/transaction"
+	"health/storage"
 )
 
 type App struct {
 	httpServer *http.Server
 
-	db     *mongo.Database
-	mailer *service_email.Mailer
+	container *Container
 }
 
 type EmailContent struct {
@@ -29,16 +33,73 @@ type EmailContent struct {
 }
 
 func InitApp() *App {
-	db := initDB()
+	// Язык ошибок и писем, если его нет ни в профиле юзера, ни в Accept-Language
+	if locale := viper.GetString("app.default_locale"); locale != "" {
+		if err := i18n.SetDefault(locale); err != nil {
+			log.Fatalf("%s", err.Error())
+		}
+	}
+
+	store := InitStorage()
 
 	mailer := service_email.NewMailer()
 
+	container := NewContainer(store, mailer)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	container.Bootstrap(ctx)
+
 	return &App{
-		db:     db,
-		mailer: mailer,
+		container: container,
 	}
 }
 
+// InitStorage выбирает хранилище по db.driver: mongo (по умолчанию) или memory для разработки без базы
+func InitStorage() *storage.Storage {
+	switch driver := viper.GetString("db.driver"); driver {
+	case "", storage.DriverMongo:
+		db := InitDB()
+
+		// * Индексы нужны до первого запроса, иначе уникальность email не гарантируется
+		if viper.GetBool("db.migrate_on_boot") {
+			migrate(migration.NewMigrator(db, migrations.All))
+		}
+
+		// * Регистрация и обновление профиля атомарны только с транзакциями, а им нужен replica set
+		transactions := viper.GetBool("db.transactions")
+		if transactions {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := transaction.CheckMongoSupport(ctx, db); err != nil {
+				log.Fatalf("Error checking db.transactions: %s. Run mongod with --replSet or set db.transactions=false", err.Error())
+			}
+		} else {
+			log.Println("db.transactions is off: sign-up and profile updates are not atomic")
+		}
+
+		return storage.NewMongoStorage(db, transactions)
+	case storage.DriverPostgres:
+		db := InitPostgres()
+
+		if viper.GetBool("db.migrate_on_boot") {
+			migrate(migration.NewSQLMigrator(db, migrations.Postgres))
+		}
+
+		return storage.NewPostgresStorage(db)
+	case storage.DriverMemory:
+		log.Println("App uses in-memory storage, data will be lost on restart")
+
+		return storage.NewMemoryStorage()
+	default:
+		log.Fatalf("Unknown db.driver: %s", driver)
+	}
+
+	return nil
+}
+
 func (app *App) Run(port string) error {
 	// Init gin handler
 	router := gin.Default()
@@ -47,7 +108,7 @@ func (app *App) Run(port string) error {
 		gin.Logger(),
 	)
 
-	routes.InitRoutes(router, app.db, app.mailer)
+	routes.InitRoutes(router, app.container.AuthMiddleware, app.container.Modules()...)
 
 	// Конфиги для сервера
 	app.httpServer = &http.Server{
@@ -79,7 +140,18 @@ func (app *App) Run(port string) error {
 	return app.httpServer.Shutdown(ctx)
 }
 
-func initDB() *mongo.Database {
+func migrate(runner migration.Runner) {
+	applied, err := runner.Up(context.Background())
+	for _, m := range applied {
+		log.Printf("Migration %d %s applied", m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatalf("Error running migrations: %s", err.Error())
+	}
+}
+
+// InitDB подключается к монге, используется и сервером, и подкомандами cli
+func InitDB() *mongo.Database {
 	dbURI := viper.GetString("db.uri")
 
 	// креды для авторизации
@@ -109,4 +181,4 @@ func initDB() *mongo.Database {
 	log.Printf("App connected to db_uri %s", dbURI)
 
 	return client.Database(viper.GetString("db.name"))
-}
\ No newline at end of file
+}
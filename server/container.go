@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"health/routes"
+	"health/routes/client/admin"
+	adminHandler "health/routes/client/admin/handler"
+	adminUseCase "health/routes/client/admin/usecase"
+	"health/routes/client/auth"
+	authHandler "health/routes/client/auth/handler"
+	authUseCase "health/routes/client/auth/usecase"
+	"health/routes/client/role"
+	roleHandler "health/routes/client/role/handler"
+	roleUseCase "health/routes/client/role/usecase"
+	"health/routes/client/userRole"
+	userRoleHandler "health/routes/client/userRole/handler"
+	userRoleUseCase "health/routes/client/userRole/usecase"
+	service_email "health/services/email"
+	"health/services/jwk"
+	"health/services/ratelimit"
+	"health/storage"
+)
+
+// Container — корень композиции: хранилище, сервисы, usecase и middleware создаются здесь
+// ровно один раз, модули получают их готовыми. Чтобы подменить реализацию, достаточно
+// поменять поле контейнера до вызова Modules.
+type Container struct {
+	Store  *storage.Storage
+	Mailer *service_email.Mailer
+	KeySet *jwk.KeySet
+
+	Auth     auth.UseCase
+	Role     role.UseCase
+	UserRole userRole.UseCase
+	Admin    admin.UseCase
+
+	AuthMiddleware gin.HandlerFunc
+	RateLimit      gin.HandlerFunc
+}
+
+func NewContainer(store *storage.Storage, mailer *service_email.Mailer) *Container {
+	// Ключи подписи токенов
+	keySet, err := jwk.LoadOrGenerateKeySet(
+		viper.GetString("auth.keys.dir"),
+		viper.GetString("auth.keys.active_kid"),
+		viper.GetBool("auth.keys.ephemeral"),
+	)
+	if err != nil {
+		log.Fatalf("Error loading signing keys: %s", err.Error())
+	}
+
+	c := &Container{
+		Store:  store,
+		Mailer: mailer,
+		KeySet: keySet,
+	}
+
+	c.Auth = newAuthUseCase(store, mailer, keySet)
+	c.Role = roleUseCase.NewUseCase(
+		store.Roles,
+		store.Users,
+		store.UserRoles,
+		c.Auth,
+	)
+	c.UserRole = userRoleUseCase.NewUseCase(
+		store.UserRoles,
+		store.Roles,
+		store.Users,
+		mailer,
+		c.Auth,
+	)
+	c.Admin = adminUseCase.NewUseCase(
+		store.Users,
+		store.Roles,
+		store.UserRoles,
+		store.Transactions,
+		c.Auth,
+	)
+
+	c.AuthMiddleware = authHandler.NewMiddleware(c.Auth)
+
+	// * Лимит на email должен быть мягче блокировки входа, иначе до ответа с SignInLock клиент не дойдет
+	byEmail := ratelimit.Limit{
+		Burst:  viper.GetInt("auth.rate_limit.email.burst"),
+		Period: time.Second * viper.GetDuration("auth.rate_limit.email.period"),
+	}
+	if threshold := viper.GetInt("auth.sign_in_lock.threshold"); byEmail.Burst > 0 && byEmail.Burst <= threshold {
+		log.Fatalf("auth.rate_limit.email.burst (%d) must be greater than auth.sign_in_lock.threshold (%d)", byEmail.Burst, threshold)
+	}
+
+	// Лимиты запросов на всю группу /auth/v1
+	c.RateLimit = authHandler.NewRateLimitMiddleware(
+		ratelimit.NewMemoryStore(),
+		ratelimit.Limit{
+			Burst:  viper.GetInt("auth.rate_limit.ip.burst"),
+			Period: time.Second * viper.GetDuration("auth.rate_limit.ip.period"),
+		},
+		byEmail,
+	)
+
+	return c
+}
+
+// Bootstrap готовит данные, без которых API не работает
+func (c *Container) Bootstrap(ctx context.Context) {
+	// * Без ролей в базе не работает регистрация, поэтому создаем их при старте
+	if err := c.Role.SeedRoles(ctx); err != nil {
+		log.Fatalf("Error seeding roles: %s", err.Error())
+	}
+
+	// * Первого админа назначаем из конфига, дальше админы выдают роли через API
+	if adminEmail := viper.GetString("auth.bootstrap_admin_email"); adminEmail != "" {
+		if err := c.Role.BootstrapAdmin(ctx, adminEmail); err != nil {
+			log.Printf("Can`t bootstrap admin: %s", err.Error())
+		}
+	}
+}
+
+// Modules — HTTP модули приложения, новый модуль достаточно добавить сюда
+func (c *Container) Modules() []routes.Module {
+	return []routes.Module{
+		authHandler.NewModule(c.Auth, c.AuthMiddleware, c.RateLimit),
+		roleHandler.NewModule(c.Role, c.AuthMiddleware),
+		userRoleHandler.NewModule(c.UserRole, c.AuthMiddleware),
+		adminHandler.NewModule(c.Admin, c.AuthMiddleware),
+	}
+}
+
+// DocsModules — модули без зависимостей: роуты и спецификацию можно собрать без базы и ключей,
+// usecase при регистрации роутов не вызываются
+func DocsModules() []routes.Module {
+	noop := func(c *gin.Context) { c.Next() }
+
+	return (&Container{AuthMiddleware: noop, RateLimit: noop}).Modules()
+}
+
+func newAuthUseCase(store *storage.Storage, mailer *service_email.Mailer, keySet *jwk.KeySet) *authUseCase.UseCase {
+	loginMode := authUseCase.LoginMode(viper.GetString("auth.login_mode"))
+	switch loginMode {
+	case "":
+		loginMode = authUseCase.LoginModePassword
+	case authUseCase.LoginModePassword, authUseCase.LoginModeCode:
+	default:
+		log.Fatalf("Unknown auth.login_mode: %s", loginMode)
+	}
+
+	return authUseCase.NewUseCase(
+		store.Users,
+		store.Sessions,
+		store.Revocations,
+		store.Roles,
+		store.UserRoles,
+		store.Transactions,
+
+		mailer,
+		keySet,
+		viper.GetDuration("auth.access_token_ttl"),
+		viper.GetDuration("auth.refresh_token_ttl"),
+		viper.GetDuration("auth.revocation_cache_ttl"),
+		viper.GetDuration("auth.user_cache_ttl"),
+		viper.GetDuration("auth.reset_password_ttl"),
+		viper.GetString("app.client_url"),
+		loginMode,
+		authUseCase.VerifyCodePolicy{
+			TTL:            time.Minute * viper.GetDuration("auth.verify_code.ttl"),
+			ResendCooldown: time.Second * viper.GetDuration("auth.verify_code.resend_cooldown"),
+			MaxAttempts:    viper.GetInt("auth.verify_code.max_attempts"),
+			Lockout:        time.Minute * viper.GetDuration("auth.verify_code.lockout"),
+		},
+		authUseCase.TwoFactorPolicy{
+			Issuer:       viper.GetString("auth.totp.issuer"),
+			ChallengeTTL: time.Minute * viper.GetDuration("auth.totp.challenge_ttl"),
+			Skew:         viper.GetInt64("auth.totp.skew"),
+			MaxAttempts:  viper.GetInt("auth.totp.max_attempts"),
+		},
+		authUseCase.SignInLockPolicy{
+			Threshold:   viper.GetInt("auth.sign_in_lock.threshold"),
+			BaseLockout: time.Minute * viper.GetDuration("auth.sign_in_lock.base_lockout"),
+			MaxLockout:  time.Minute * viper.GetDuration("auth.sign_in_lock.max_lockout"),
+		},
+	)
+}
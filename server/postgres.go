@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	// * драйвер postgres для database/sql
+	_ "github.com/lib/pq"
+	"github.com/spf13/viper"
+)
+
+// InitPostgres подключается к postgres по db.postgres.dsn
+func InitPostgres() *sql.DB {
+	db, err := sql.Open("postgres", viper.GetString("db.postgres.dsn"))
+	if err != nil {
+		log.Fatalf("Error occurred while establishing connection to postgres")
+	}
+
+	db.SetMaxOpenConns(viper.GetInt("db.postgres.max_open_conns"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("App connected to postgres")
+
+	return db
+}
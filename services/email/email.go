@@ -2,9 +2,13 @@ package email
 
 import (
 	"bytes"
+	"fmt"
+	"health/services/i18n"
+	"health/shared/errs"
 	"health/shared/types"
 	"html/template"
 	"log"
+	"mime"
 	"net/smtp"
 	"os"
 	"path/filepath"
@@ -50,49 +54,60 @@ type Message struct {
 	To           []string
 	Content      interface{}
 	TemplateName string
+	// Locale — язык шаблона, тема письма переводится вызывающим кодом
+	Locale i18n.Locale
 }
 
 // Send sends the mail via smtp.
 func (m *Mailer) Send(email *Message) *types.Error {
-	body, err := ParseTemplate("./templates/email", email.TemplateName, email.Content)
+	body, err := ParseTemplate("./templates/email", email.TemplateName, email.Locale, email.Content)
 
 	if err != nil {
 		log.Println("Error parsing template:", err)
 
 		return &types.Error{
-			Message: err.Error(),
-			Field:   "template",
-			Tag:     "email",
+			Code:  errs.Internal,
+			Field: "template",
+			Tag:   "email",
+			Cause: err,
 		}
 	}
 
-	var to = email.To[0]
-
-	message := []byte(
-		"To: " + to + "\r\n" +
-			"Subject: " + email.Subject + "\r\n" +
-			"MIME-Version: 1.0\r\n" +
-			"Content-Type: text/html; charset=\"utf-8\"\r\n" + "\r\n" +
-			body,
-	)
-
-	err = smtp.SendMail(m.smtpConfig.Address(), m.auth, m.smtpConfig.FROM, email.To, message)
+	err = smtp.SendMail(m.smtpConfig.Address(), m.auth, m.smtpConfig.FROM, email.To, buildMessage(email.To[0], email.Subject, body))
 
 	if err != nil {
 		log.Println("Error sengind message", err)
 
 		return &types.Error{
-			Message: err.Error(),
-			Field:   "message",
-			Tag:     "email",
+			Code:  errs.Internal,
+			Field: "message",
+			Tag:   "email",
+			Cause: err,
 		}
 	}
 
 	return nil
 }
 
-func ParseTemplate(templateDir string, TemplateName string, data interface{}) (string, error) {
-	templates := template.New("")
+// buildMessage собирает письмо: заголовки только ASCII, поэтому переведённая тема
+// кодируется по RFC 2047, а кодировка тела объявляется явно.
+func buildMessage(to, subject, body string) []byte {
+	return []byte(
+		"To: " + to + "\r\n" +
+			"Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n" +
+			"MIME-Version: 1.0\r\n" +
+			"Content-Type: text/html; charset=UTF-8\r\n" +
+			"Content-Transfer-Encoding: 8bit\r\n" + "\r\n" +
+			body,
+	)
+}
+
+// ParseTemplate рендерит шаблон на языке locale. Тексты в шаблонах берутся через
+// {{t "key" "name" value}}: значения экранируются, сам перевод может содержать разметку.
+func ParseTemplate(templateDir string, TemplateName string, locale i18n.Locale, data interface{}) (string, error) {
+	templates := template.New("").Funcs(template.FuncMap{
+		"t": translator(locale),
+	})
 
 	err := filepath.Walk(templateDir, func(path string, info os.FileInfo, err error) error {
 		if !info.IsDir() && strings.HasSuffix(path, ".html") {
@@ -112,4 +127,16 @@ func ParseTemplate(templateDir string, TemplateName string, data interface{}) (s
 	}
 
 	return buf.String(), nil
-}
\ No newline at end of file
+}
+
+func translator(locale i18n.Locale) func(key string, args ...interface{}) template.HTML {
+	return func(key string, args ...interface{}) template.HTML {
+		pairs := make([]string, len(args))
+		for i, arg := range args {
+			pairs[i] = template.HTMLEscapeString(fmt.Sprint(arg))
+		}
+
+		// * Переводы — наши файлы, им доверяем; пользовательские значения уже экранированы
+		return template.HTML(i18n.T(locale, key, pairs...)) //nolint:gosec
+	}
+}
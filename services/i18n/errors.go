@@ -0,0 +1,43 @@
+package i18n
+
+import (
+	"health/shared/errs"
+	"health/shared/types"
+)
+
+// ErrorKey — ключ перевода сообщения ошибки с кодом code
+func ErrorKey(code errs.Code) string {
+	return "error." + string(code)
+}
+
+// FieldMessage — сообщение ошибки поля по ее ключу перевода
+func FieldMessage(locale Locale, fe types.FieldError) string {
+	return T(locale, fe.Key, "field", fe.Field, "param", fe.Param)
+}
+
+// Localize возвращает копию ошибки с сообщениями на языке locale.
+// Для кода без перевода остается исходный текст, исходная ошибка из каталога не меняется.
+func Localize(locale Locale, err *types.Error) *types.Error {
+	localized := *err
+
+	if len(err.Fields) > 0 {
+		localized.Fields = make([]types.FieldError, len(err.Fields))
+		for i, fe := range err.Fields {
+			if fe.Key != "" {
+				fe.Message = FieldMessage(locale, fe)
+			}
+			localized.Fields[i] = fe
+		}
+
+		// * Как и в validation: верхнеуровневое сообщение повторяет первую ошибку поля
+		localized.Message = localized.Fields[0].Message
+
+		return &localized
+	}
+
+	if message, ok := Lookup(locale, ErrorKey(err.Code)); ok {
+		localized.Message = message
+	}
+
+	return &localized
+}
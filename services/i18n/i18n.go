@@ -0,0 +1,203 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale — язык сообщений: ошибок API и писем
+type Locale string
+
+const (
+	RU Locale = "ru"
+	KK Locale = "kk"
+	EN Locale = "en"
+)
+
+// * en — эталонный словарь, если в другом языке ключа нет, берем текст из него
+const fallback = EN
+
+//go:embed locales/*.json
+var files embed.FS
+
+var (
+	bundles       = map[Locale]map[string]string{}
+	defaultLocale = EN
+)
+
+func init() {
+	for _, locale := range Supported() {
+		raw, err := files.ReadFile("locales/" + string(locale) + ".json")
+		if err != nil {
+			panic(err)
+		}
+
+		bundle := map[string]string{}
+		if err := json.Unmarshal(raw, &bundle); err != nil {
+			panic(fmt.Sprintf("i18n: %s.json: %v", locale, err))
+		}
+
+		bundles[locale] = bundle
+	}
+}
+
+// Supported — языки, для которых есть словари
+func Supported() []Locale {
+	return []Locale{RU, KK, EN}
+}
+
+// SetDefault — язык для запросов без Accept-Language и юзеров без предпочтения
+func SetDefault(value string) error {
+	locale, ok := Parse(value)
+	if !ok {
+		return fmt.Errorf("i18n: unsupported default locale %q", value)
+	}
+
+	defaultLocale = locale
+
+	return nil
+}
+
+func Default() Locale {
+	return defaultLocale
+}
+
+// Parse понимает теги вида ru, ru-RU, kk_KZ. kz — частая ошибка вместо kk, принимаем и его.
+func Parse(value string) (Locale, bool) {
+	tag := strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "kz" {
+		tag = string(KK)
+	}
+
+	if _, ok := bundles[Locale(tag)]; ok {
+		return Locale(tag), true
+	}
+
+	return "", false
+}
+
+// Match выбирает язык по заголовку Accept-Language с учетом q, иначе язык по умолчанию
+func Match(acceptLanguage string) Locale {
+	type candidate struct {
+		locale Locale
+		q      float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+
+		locale, ok := Parse(fields[0])
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = value
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		candidates = append(candidates, candidate{locale, q})
+	}
+
+	if len(candidates) == 0 {
+		return defaultLocale
+	}
+
+	// * При равном q выигрывает язык, который клиент указал раньше
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	return candidates[0].locale
+}
+
+// Lookup — текст по ключу, при отсутствии перевода текст из en
+func Lookup(locale Locale, key string) (string, bool) {
+	if text, ok := bundles[locale][key]; ok {
+		return text, true
+	}
+
+	text, ok := bundles[fallback][key]
+
+	return text, ok
+}
+
+// T — текст по ключу с подстановкой {name} из пар name, value. Неизвестный ключ возвращается как есть.
+func T(locale Locale, key string, args ...string) string {
+	text, ok := Lookup(locale, key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return text
+	}
+
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+args[i]+"}", args[i+1])
+	}
+
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Missing — ключи из en, которых нет в словаре locale
+func Missing(locale Locale) []string {
+	var keys []string
+	for key := range bundles[fallback] {
+		if _, ok := bundles[locale][key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+type ctxKey struct{}
+
+// WithLocale кладет язык запроса в контекст, его читают обработчик ошибок и usecase при отправке писем
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, ctxKey{}, locale)
+}
+
+// FromContext — язык запроса или язык по умолчанию
+func FromContext(ctx context.Context) Locale {
+	if locale, ok := ctx.Value(ctxKey{}).(Locale); ok {
+		return locale
+	}
+
+	return defaultLocale
+}
+
+// Preferred — язык, выбранный юзером в профиле, а если его нет, язык запроса
+func Preferred(ctx context.Context, preference string) Locale {
+	if locale, ok := Parse(preference); ok {
+		return locale
+	}
+
+	return FromContext(ctx)
+}
+
+// ForUser — язык письма без контекста запроса юзера, например решение админа по заявке
+func ForUser(preference string) Locale {
+	if locale, ok := Parse(preference); ok {
+		return locale
+	}
+
+	return defaultLocale
+}
@@ -0,0 +1,106 @@
+package iin
+
+import (
+	"errors"
+	"health/models"
+	"time"
+)
+
+// ИИН Казахстана — 12 цифр: ГГММДД, цифра века и пола, 4 цифры порядкового номера, контрольная цифра
+
+const Length = 12
+
+var (
+	ErrFormat   = errors.New("IIN must be 12 digits")
+	ErrDate     = errors.New("IIN contains an invalid date of birth")
+	ErrCentury  = errors.New("IIN contains an invalid century digit")
+	ErrChecksum = errors.New("IIN checksum is invalid")
+)
+
+var (
+	firstWeights  = [Length - 1]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+	secondWeights = [Length - 1]int{3, 4, 5, 6, 7, 8, 9, 10, 11, 1, 2}
+)
+
+// Info — то, что закодировано в ИИН
+type Info struct {
+	Birthday time.Time
+	Gender   models.Gender
+}
+
+// Parse проверяет формат, дату, цифру века и контрольную сумму и возвращает дату рождения и пол
+func Parse(value string) (*Info, error) {
+	if len(value) != Length {
+		return nil, ErrFormat
+	}
+
+	digits := make([]int, Length)
+	for i, r := range value {
+		if r < '0' || r > '9' {
+			return nil, ErrFormat
+		}
+		digits[i] = int(r - '0')
+	}
+
+	if !validChecksum(digits) {
+		return nil, ErrChecksum
+	}
+
+	// * 7-я цифра: 1/2 — XIX век, 3/4 — XX, 5/6 — XXI; нечетная — мужчина, четная — женщина
+	centuryDigit := digits[6]
+	if centuryDigit < 1 || centuryDigit > 6 {
+		return nil, ErrCentury
+	}
+	century := 1800 + (centuryDigit-1)/2*100
+
+	year := century + digits[0]*10 + digits[1]
+	month := time.Month(digits[2]*10 + digits[3])
+	day := digits[4]*10 + digits[5]
+
+	birthday := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	// * time.Date нормализует 31 февраля в 3 марта, такую дату считаем неверной
+	if birthday.Month() != month || birthday.Day() != day {
+		return nil, ErrDate
+	}
+
+	gender := models.Male
+	if centuryDigit%2 == 0 {
+		gender = models.Female
+	}
+
+	return &Info{
+		Birthday: birthday,
+		Gender:   gender,
+	}, nil
+}
+
+// validChecksum — контрольная цифра считается весами 1..11 по модулю 11,
+// если вышло 10 — весами 3..11,1,2, если снова 10 — такой номер не выдается
+func validChecksum(digits []int) bool {
+	check := weightedSum(digits, firstWeights) % 11
+	if check == 10 {
+		check = weightedSum(digits, secondWeights) % 11
+		if check == 10 {
+			return false
+		}
+	}
+
+	return check == digits[Length-1]
+}
+
+func weightedSum(digits []int, weights [Length - 1]int) int {
+	sum := 0
+	for i, weight := range weights {
+		sum += digits[i] * weight
+	}
+
+	return sum
+}
+
+// SameDate — совпадает ли дата рождения из ИИН с указанной, время и часовой пояс не важны
+func (info *Info) SameDate(birthday time.Time) bool {
+	y1, m1, d1 := info.Birthday.Date()
+	y2, m2, d2 := birthday.Date()
+
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
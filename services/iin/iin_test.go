@@ -0,0 +1,74 @@
+package iin_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"health/models"
+	"health/services/iin"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		birthday time.Time
+		gender   models.Gender
+	}{
+		{"XX century, male", "920303300009", time.Date(1992, time.March, 3, 0, 0, 0, 0, time.UTC), models.Male},
+		{"XX century, female", "920303400015", time.Date(1992, time.March, 3, 0, 0, 0, 0, time.UTC), models.Female},
+		// * Ведущий ноль: родившиеся в 2000-х, год 05 и 00
+		{"XXI century, leading zero", "050101500000", time.Date(2005, time.January, 1, 0, 0, 0, 0, time.UTC), models.Male},
+		{"XXI century, leap day", "000229600014", time.Date(2000, time.February, 29, 0, 0, 0, 0, time.UTC), models.Female},
+		// * Первые веса дают 10, контрольная цифра считается вторыми
+		{"second weights", "850615400705", time.Date(1985, time.June, 15, 0, 0, 0, 0, time.UTC), models.Female},
+		{"second weights, leading zero", "050101500103", time.Date(2005, time.January, 1, 0, 0, 0, 0, time.UTC), models.Male},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := iin.Parse(tt.value)
+			if err != nil {
+				t.Fatalf("Parse(%s): %v", tt.value, err)
+			}
+			if !info.SameDate(tt.birthday) {
+				t.Errorf("Parse(%s) birthday = %s, want %s", tt.value, info.Birthday.Format("2006-01-02"), tt.birthday.Format("2006-01-02"))
+			}
+			if info.Gender != tt.gender {
+				t.Errorf("Parse(%s) gender = %v, want %v", tt.value, info.Gender, tt.gender)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		err   error
+	}{
+		{"too short", "85061540000", iin.ErrFormat},
+		{"too long", "8506154000060", iin.ErrFormat},
+		{"not digits", "85061540000a", iin.ErrFormat},
+		{"wrong check digit", "920303300008", iin.ErrChecksum},
+		// * Обе суммы дают 10, такой номер не выдается, какой бы ни была последняя цифра
+		{"checksum is 10", "850615400780", iin.ErrChecksum},
+		{"checksum is 10, leading zero", "050101500190", iin.ErrChecksum},
+		{"century digit 7", "851231700000", iin.ErrCentury},
+		{"century digit 0", "851231000016", iin.ErrCentury},
+		{"31 february", "900231300004", iin.ErrDate},
+		{"month 13", "901301300017", iin.ErrDate},
+		{"29 february of 2001", "010229600016", iin.ErrDate},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := iin.Parse(tt.value); !errors.Is(err, tt.err) {
+				t.Errorf("Parse(%s) = %v, want %v", tt.value, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package jwk
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	// activeFile хранит kid ключа, которым сейчас подписываются токены
+	activeFile = "active"
+
+	privateKeySuffix = ".pem"
+	publicKeySuffix  = ".pub.pem"
+
+	rsaKeyBits = 2048
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown key algorithm")
+	ErrUnsupportedKey   = errors.New("unsupported key type")
+	ErrNoSigningKey     = errors.New("no active signing key")
+)
+
+// Key — ключ подписи токенов. У ключей, оставленных только для проверки, Private == nil.
+type Key struct {
+	ID        string
+	Algorithm string
+
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+func (k *Key) SigningMethod() jwt.SigningMethod {
+	if k.Algorithm == AlgEdDSA {
+		return SigningMethodEd25519
+	}
+
+	return jwt.SigningMethodRS256
+}
+
+// KeySet — активный ключ подписи плюс все ключи, которые еще принимаются при проверке
+type KeySet struct {
+	active *Key
+	keys   map[string]*Key
+}
+
+func NewKeySet(active *Key, keys ...*Key) *KeySet {
+	set := &KeySet{
+		active: active,
+		keys:   map[string]*Key{active.ID: active},
+	}
+	for _, key := range keys {
+		set.keys[key.ID] = key
+	}
+
+	return set
+}
+
+func (s *KeySet) SigningKey() *Key {
+	return s.active
+}
+
+func (s *KeySet) VerificationKey(kid string) (*Key, bool) {
+	key, ok := s.keys[kid]
+
+	return key, ok
+}
+
+// LoadKeySet читает ключи из dir: <kid>.pem — приватные (PKCS#8), <kid>.pub.pem — только для проверки.
+// Активный ключ берется из activeKID, а если он пустой — из файла dir/active.
+func LoadKeySet(dir string, activeKID string) (*KeySet, error) {
+	keys, err := ReadKeys(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if activeKID == "" {
+		activeKID, err = ReadActiveKID(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	active, ok := keys[activeKID]
+	if !ok || active.Private == nil {
+		return nil, fmt.Errorf("%w: %q in %s", ErrNoSigningKey, activeKID, dir)
+	}
+
+	set := &KeySet{
+		active: active,
+		keys:   keys,
+	}
+
+	return set, nil
+}
+
+// LoadOrGenerateKeySet — как LoadKeySet, но для локальной разработки при ephemeral создает временный
+// Ed25519 ключ, если ключей нет. Без ephemeral отсутствие ключей — ошибка: временный ключ на проде
+// сбрасывает все токены при рестарте, а у реплик ключи разные.
+func LoadOrGenerateKeySet(dir string, activeKID string, ephemeral bool) (*KeySet, error) {
+	set, err := LoadKeySet(dir, activeKID)
+	if err == nil {
+		return set, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if !ephemeral {
+		return nil, fmt.Errorf("%w in %q: create one with `keys generate -dir %s`: %v", ErrNoSigningKey, dir, dir, err)
+	}
+
+	log.Printf("No signing keys in %s, using an ephemeral key: tokens will not survive restart", dir)
+
+	key, err := GenerateKey(AlgEdDSA)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeySet(key), nil
+}
+
+func ReadKeys(dir string) (map[string]*Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*Key)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, privateKeySuffix) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		var key *Key
+		if strings.HasSuffix(name, publicKeySuffix) {
+			key, err = parsePublicKey(strings.TrimSuffix(name, publicKeySuffix), data)
+		} else {
+			key, err = parsePrivateKey(strings.TrimSuffix(name, privateKeySuffix), data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		// * Приватный ключ важнее публичного с тем же kid
+		if existing, ok := keys[key.ID]; ok && existing.Private != nil {
+			continue
+		}
+		keys[key.ID] = key
+	}
+
+	return keys, nil
+}
+
+func ReadActiveKID(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, activeFile))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+func WriteActiveKID(dir string, kid string) error {
+	return os.WriteFile(filepath.Join(dir, activeFile), []byte(kid+"\n"), 0o600)
+}
+
+// GenerateKey создает новый ключ с kid вида 20060102-<random>
+func GenerateKey(alg string) (*Key, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	kid := time.Now().UTC().Format("20060102") + "-" + hex.EncodeToString(suffix)
+
+	switch alg {
+	case AlgEdDSA:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Key{ID: kid, Algorithm: AlgEdDSA, Private: private, Public: public}, nil
+	case AlgRS256:
+		private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Key{ID: kid, Algorithm: AlgRS256, Private: private, Public: &private.PublicKey}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, alg)
+}
+
+// WriteKey сохраняет приватный ключ в dir/<kid>.pem
+func WriteKey(dir string, key *Key) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	return os.WriteFile(filepath.Join(dir, key.ID+privateKeySuffix), data, 0o600)
+}
+
+// SortedIDs — kid всех ключей набора, по kid они же сортируются по дате создания
+func SortedIDs(keys map[string]*Key) []string {
+	ids := make([]string, 0, len(keys))
+	for id := range keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+func parsePrivateKey(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrUnsupportedKey
+	}
+
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := private.(type) {
+	case ed25519.PrivateKey:
+		return &Key{ID: kid, Algorithm: AlgEdDSA, Private: k, Public: k.Public()}, nil
+	case *rsa.PrivateKey:
+		return &Key{ID: kid, Algorithm: AlgRS256, Private: k, Public: &k.PublicKey}, nil
+	}
+
+	return nil, ErrUnsupportedKey
+}
+
+func parsePublicKey(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrUnsupportedKey
+	}
+
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := public.(type) {
+	case ed25519.PublicKey:
+		return &Key{ID: kid, Algorithm: AlgEdDSA, Public: k}, nil
+	case *rsa.PublicKey:
+		return &Key{ID: kid, Algorithm: AlgRS256, Public: k}, nil
+	}
+
+	return nil, ErrUnsupportedKey
+}
+
+// JSONWebKey — публичная часть ключа в формате RFC 7517
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS отдает все ключи, которыми можно проверить выданные токены
+func (s *KeySet) JWKS() *JSONWebKeySet {
+	set := &JSONWebKeySet{
+		Keys: []JSONWebKey{},
+	}
+
+	for _, id := range SortedIDs(s.keys) {
+		key := s.keys[id]
+		jwk := JSONWebKey{
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: key.Algorithm,
+		}
+
+		switch k := key.Public.(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(k)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
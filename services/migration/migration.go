@@ -0,0 +1,135 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection — сюда записываются примененные миграции
+var Collection string = "migrations"
+
+// Migration — одна версия схемы. Up должна быть идемпотентной:
+// если процесс упадет после Up, но до записи в migrations, она выполнится еще раз.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+}
+
+// Status — состояние миграции для `migrate status`
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time // пустое, если миграция еще не применена
+}
+
+type record struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// Runner — общее у Migrator (монга) и SQLMigrator (postgres)
+type Runner interface {
+	Up(ctx context.Context) ([]Migration, error)
+	Status(ctx context.Context) ([]Status, error)
+}
+
+type Migrator struct {
+	db         *mongo.Database
+	migrations []Migration
+}
+
+func NewMigrator(db *mongo.Database, migrations []Migration) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	return &Migrator{
+		db:         db,
+		migrations: sorted,
+	}
+}
+
+// Up применяет по порядку все еще не примененные миграции и возвращает их
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := migration.Up(ctx, m.db); err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		// * upsert, а не insert: два инстанса могли одновременно применить одну и ту же миграцию
+		_, err := m.db.Collection(Collection).ReplaceOne(ctx,
+			bson.M{"_id": migration.Version},
+			record{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			},
+			options.Replace().SetUpsert(true),
+		)
+		if err != nil {
+			return done, err
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, Status{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: applied[migration.Version],
+		})
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]time.Time, error) {
+	cur, err := m.db.Collection(Collection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	applied := make(map[int]time.Time)
+	for cur.Next(ctx) {
+		rec := new(record)
+		if err := cur.Decode(rec); err != nil {
+			return nil, err
+		}
+
+		applied[rec.Version] = rec.AppliedAt
+	}
+
+	return applied, cur.Err()
+}
@@ -0,0 +1,187 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLTable — сюда записываются примененные sql миграции
+var SQLTable string = "schema_migrations"
+
+// SQLMigrator применяет файлы вида 0001_name.sql из fsys по порядку номеров.
+// Каждый файл выполняется в своей транзакции вместе с записью в SQLTable.
+type SQLMigrator struct {
+	db   *sql.DB
+	fsys fs.FS
+}
+
+func NewSQLMigrator(db *sql.DB, fsys fs.FS) *SQLMigrator {
+	return &SQLMigrator{
+		db:   db,
+		fsys: fsys,
+	}
+}
+
+type sqlMigration struct {
+	Migration
+	file string
+}
+
+func (m *SQLMigrator) Up(ctx context.Context) ([]Migration, error) {
+	migrations, err := m.migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.createTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		applied, err := m.apply(ctx, migration)
+		if err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		if applied {
+			done = append(done, migration.Migration)
+		}
+	}
+
+	return done, nil
+}
+
+func (m *SQLMigrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, err := m.migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.createTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version, applied_at FROM "+SQLTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, migration := range migrations {
+		statuses = append(statuses, Status{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: applied[migration.Version],
+		})
+	}
+
+	return statuses, nil
+}
+
+func (m *SQLMigrator) createTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+SQLTable+` (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL
+	)`)
+
+	return err
+}
+
+func (m *SQLMigrator) apply(ctx context.Context, migration sqlMigration) (bool, error) {
+	query, err := fs.ReadFile(m.fsys, migration.file)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback() //nolint:errcheck // после Commit вернет ErrTxDone
+
+	// * Два инстанса, стартующие одновременно, применяют миграции по очереди
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE "+SQLTable+" IN EXCLUSIVE MODE"); err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+SQLTable+" WHERE version = $1)", migration.Version).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, string(query)); err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO "+SQLTable+" (version, name, applied_at) VALUES ($1, $2, $3)",
+		migration.Version, migration.Name, time.Now(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// migrations читает файлы NNNN_name.sql, номер — версия миграции
+func (m *SQLMigrator) migrations() ([]sqlMigration, error) {
+	files, err := fs.Glob(m.fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []sqlMigration
+	for _, file := range files {
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(file, ".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("migration file %s: expected NNNN_name.sql", file)
+		}
+
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s: %w", file, err)
+		}
+
+		migrations = append(migrations, sqlMigration{
+			Migration: Migration{
+				Version: version,
+				Name:    strings.ReplaceAll(name, "_", " "),
+			},
+			file: file,
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
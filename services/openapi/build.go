@@ -0,0 +1,232 @@
+package openapi
+
+import (
+	"health/shared/errs"
+	"health/shared/types"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Endpoint — описание роута рядом с его регистрацией в модуле. Схемы берутся из Go типов
+// входа и ответа, поэтому спецификация меняется вместе с кодом.
+type Endpoint struct {
+	Method string
+	// Path — путь как в gin, :id становится параметром {id}
+	Path    string
+	Tag     string
+	Summary string
+
+	// Auth — нужен access токен в заголовке Authorization
+	Auth bool
+	// Permission — право, которое проверяет роут, кроме токена
+	Permission string
+
+	// Body — структура JSON тела, Query — структура query параметров с тегами form
+	Body  interface{}
+	Query interface{}
+
+	// Data — тип поля data в GoodResponse, nil — ответ 200 без тела.
+	// Raw — ответ без обертки GoodResponse, например JWKS.
+	Data interface{}
+	Raw  interface{}
+	// Text — ответ 200 простым текстом
+	Text bool
+
+	// Errors — коды ошибок самого роута. Ошибки разбора тела, авторизации и internal добавляются сами.
+	Errors []errs.Code
+}
+
+const (
+	jsonContent   = "application/json"
+	securityToken = "accessToken"
+)
+
+// Build собирает документ из описаний роутов всех модулей
+func Build(info Info, endpoints []Endpoint) *Document {
+	s := newSchemas()
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				securityToken: {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        "Authorization",
+					Description: "Access token without the Bearer prefix",
+				},
+			},
+		},
+	}
+
+	badResponse := s.of(types.BadResponse{})
+
+	for _, endpoint := range endpoints {
+		path, params := pathParameters(endpoint.Path)
+
+		op := &Operation{
+			Summary:     endpoint.Summary,
+			OperationID: operationID(endpoint.Method, path),
+			Parameters:  params,
+			Responses:   map[string]*Response{},
+		}
+		if endpoint.Tag != "" {
+			op.Tags = []string{endpoint.Tag}
+		}
+		if endpoint.Permission != "" {
+			op.Description = "Requires permission `" + endpoint.Permission + "`."
+		}
+		if endpoint.Auth {
+			op.Security = []map[string][]string{{securityToken: {}}}
+		}
+
+		if endpoint.Query != nil {
+			op.Parameters = append(op.Parameters, s.queryParameters(endpoint.Query)...)
+		}
+		if endpoint.Body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{jsonContent: {Schema: s.of(endpoint.Body)}},
+			}
+		}
+
+		op.Responses[strconv.Itoa(http.StatusOK)] = success(s, endpoint)
+		for status, codes := range errorCodes(endpoint) {
+			op.Responses[strconv.Itoa(status)] = failure(badResponse, status, codes)
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(endpoint.Method)] = op
+	}
+
+	doc.Components.Schemas = s.components
+
+	return doc
+}
+
+func success(s *schemas, endpoint Endpoint) *Response {
+	switch {
+	case endpoint.Raw != nil:
+		return &Response{
+			Description: "OK",
+			Content:     map[string]MediaType{jsonContent: {Schema: s.of(endpoint.Raw)}},
+		}
+	case endpoint.Text:
+		return &Response{
+			Description: "OK",
+			Content:     map[string]MediaType{"text/plain": {Schema: &Schema{Type: "string"}}},
+		}
+	case endpoint.Data != nil:
+		// * GoodResponse с конкретным типом data вместо interface{}
+		return &Response{
+			Description: "OK",
+			Content: map[string]MediaType{jsonContent: {Schema: &Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"code": {Type: "integer"},
+					"data": s.of(endpoint.Data),
+				},
+				Required: []string{"code", "data"},
+			}}},
+		}
+	}
+
+	return &Response{Description: "OK, empty body"}
+}
+
+// errorCodes — коды ошибок роута по HTTP статусам, как их отдает обработчик ошибок
+func errorCodes(endpoint Endpoint) map[int][]errs.Code {
+	codes := append([]errs.Code{errs.Internal}, endpoint.Errors...)
+	if endpoint.Body != nil || endpoint.Query != nil {
+		codes = append(codes, errs.InvalidInput, errs.Validation)
+	}
+	if endpoint.Auth {
+		codes = append(codes, errs.AuthUnauthorized, errs.AuthAccessTokenInvalid, errs.AuthAccessTokenExpired, errs.AuthAccessTokenRevoked, errs.AuthUserSuspended)
+	}
+	if endpoint.Permission != "" {
+		codes = append(codes, errs.RoleForbidden)
+	}
+
+	byStatus := map[int][]errs.Code{}
+	seen := map[errs.Code]bool{}
+	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		status := errs.Status(code)
+		byStatus[status] = append(byStatus[status], code)
+	}
+
+	for _, list := range byStatus {
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	}
+
+	return byStatus
+}
+
+func failure(badResponse *Schema, status int, codes []errs.Code) *Response {
+	names := make([]string, len(codes))
+	for i, code := range codes {
+		names[i] = "`" + string(code) + "`"
+	}
+
+	response := &Response{
+		Description: http.StatusText(status) + ". Error codes: " + strings.Join(names, ", "),
+		Content:     map[string]MediaType{jsonContent: {Schema: badResponse}},
+	}
+
+	if status == http.StatusTooManyRequests {
+		response.Headers = map[string]*Header{
+			"Retry-After": {Description: "Seconds to wait before retrying", Schema: &Schema{Type: "integer"}},
+		}
+	}
+
+	return response
+}
+
+// pathParameters переводит путь gin в путь OpenAPI: /users/:id -> /users/{id}
+func pathParameters(ginPath string) (string, []Parameter) {
+	var params []Parameter
+
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// operationID — стабильный id операции для генераторов клиентов: post /auth/v1/sign-in -> postAuthV1SignIn
+func operationID(method string, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Route — зарегистрированный роут: метод и путь gin
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Diff сравнивает роуты роутера с документом и возвращает расхождения, пустой список — все совпадает.
+// Роуты из ignore (сама спецификация, Swagger UI) в документ не попадают и не проверяются.
+func Diff(doc *Document, routes []Route, ignore ...string) []string {
+	skip := map[string]bool{}
+	for _, path := range ignore {
+		skip[path] = true
+	}
+
+	var problems []string
+
+	registered := map[string]bool{}
+	for _, route := range routes {
+		if skip[route.Path] || route.Method == "HEAD" {
+			continue
+		}
+
+		path, _ := pathParameters(route.Path)
+		key := strings.ToLower(route.Method) + " " + path
+		registered[key] = true
+
+		if item, ok := doc.Paths[path]; !ok || (*item)[strings.ToLower(route.Method)] == nil {
+			problems = append(problems, fmt.Sprintf("%s %s is registered but not documented", route.Method, path))
+		}
+	}
+
+	for path, item := range doc.Paths {
+		for method := range *item {
+			if !registered[method+" "+path] {
+				problems = append(problems, fmt.Sprintf("%s %s is documented but not registered", strings.ToUpper(method), path))
+			}
+		}
+	}
+
+	sort.Strings(problems)
+
+	return problems
+}
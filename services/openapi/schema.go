@@ -0,0 +1,272 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+)
+
+// schemas — генератор схем по Go типам. Именованные структуры попадают в components
+// под именем пакет.Тип, например auth.SignUpInput, и дальше на них ссылаются через $ref.
+type schemas struct {
+	components map[string]*Schema
+	types      map[string]reflect.Type
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: map[string]*Schema{},
+		types:      map[string]reflect.Type{},
+	}
+}
+
+// of — схема для значения v, nil дает пустую схему (любое значение)
+func (s *schemas) of(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+
+	return s.typeOf(reflect.TypeOf(v))
+}
+
+func (s *schemas) typeOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIDType:
+		return &Schema{Type: "string", Pattern: "^[0-9a-f]{24}$"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.typeOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.typeOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.ref(t)
+	}
+
+	// * interface{} и все остальное — любое значение
+	return &Schema{}
+}
+
+func (s *schemas) ref(t reflect.Type) *Schema {
+	name := componentName(t)
+
+	// * Два разных типа под одним именем перезаписали бы схемы друг друга
+	if known, ok := s.types[name]; ok && known != t {
+		panic("openapi: schema name " + name + " is used by " + known.PkgPath() + " and " + t.PkgPath())
+	}
+	s.types[name] = t
+
+	if _, ok := s.components[name]; !ok {
+		// * Сначала резервируем имя, чтобы рекурсивные типы не зацикливались
+		s.components[name] = &Schema{}
+		*s.components[name] = *s.object(t)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName — имя схемы по имени пакета. Пакеты handler модулей называются по модулю:
+// routes/client/auth/handler -> authHandler, поэтому их ответы не пересекаются.
+func componentName(t reflect.Type) string {
+	segments := strings.Split(t.PkgPath(), "/")
+	pkg := segments[len(segments)-1]
+	if pkg == "handler" && len(segments) > 1 {
+		pkg = segments[len(segments)-2] + "Handler"
+	}
+
+	return pkg + "." + t.Name()
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{},
+	}
+
+	s.addFields(schema, t)
+
+	return schema
+}
+
+func (s *schemas) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		// * Встроенная структура без json имени раскладывается в поля родителя
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				s.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property, required := s.field(field)
+		schema.Properties[name] = property
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// field — схема поля с ограничениями из тега validate
+func (s *schemas) field(field reflect.StructField) (*Schema, bool) {
+	schema := s.typeOf(field.Type)
+
+	rules := field.Tag.Get("validate")
+	if rules == "" {
+		return schema, false
+	}
+
+	// * У $ref не бывает соседних ключей, ограничения вешаем только на простые типы
+	if schema.Ref != "" {
+		return schema, hasRule(rules, "required")
+	}
+
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		// * Правила после dive относятся к элементам, а не к самому полю
+		if rule == "dive" {
+			break
+		}
+
+		tag, param := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			tag, param = rule[:i], rule[i+1:]
+		}
+
+		switch tag {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "numeric", "number":
+			schema.Pattern = "^[0-9]+$"
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "min", "max", "len":
+			limit(schema, tag, param)
+		}
+	}
+
+	return schema, required
+}
+
+func limit(schema *Schema, tag string, param string) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	if schema.Type == "string" || schema.Type == "array" {
+		n := int(value)
+		switch tag {
+		case "min":
+			schema.MinLength = &n
+		case "max":
+			schema.MaxLength = &n
+		case "len":
+			schema.MinLength, schema.MaxLength = &n, &n
+		}
+		return
+	}
+
+	switch tag {
+	case "min":
+		schema.Minimum = &value
+	case "max":
+		schema.Maximum = &value
+	}
+}
+
+func hasRule(rules string, name string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		if rule == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// jsonName — имя из тега json, как его читает encoding/json; skip — поле в json не попадает
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+
+	return strings.SplitN(tag, ",", 2)[0], false
+}
+
+// queryParameters — параметры query строки из полей с тегом form
+func (s *schemas) queryParameters(v interface{}) []Parameter {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := strings.SplitN(field.Tag.Get("form"), ",", 2)[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		schema, required := s.field(field)
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "query",
+			Required: required,
+			Schema:   schema,
+		})
+	}
+
+	return params
+}
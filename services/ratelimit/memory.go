@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens   float64
+	updateAt time.Time
+	period   time.Duration
+}
+
+// MemoryStore — бакеты в памяти процесса
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPurge time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		lastPurge: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	if limit.Burst <= 0 || limit.Period <= 0 {
+		return true, 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.purge(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{
+			tokens:   float64(limit.Burst),
+			updateAt: now,
+			period:   limit.Period,
+		}
+		s.buckets[key] = b
+	}
+
+	// * Доливаем токены за прошедшее время, но не больше Burst
+	interval := limit.interval()
+	b.tokens += float64(now.Sub(b.updateAt)) / float64(interval)
+	if b.tokens > float64(limit.Burst) {
+		b.tokens = float64(limit.Burst)
+	}
+	b.updateAt = now
+
+	if b.tokens < 1 {
+		retryAfter := time.Duration((1 - b.tokens) * float64(interval))
+		return false, retryAfter, nil
+	}
+
+	b.tokens--
+
+	return true, 0, nil
+}
+
+// purge раз в минуту удаляет бакеты, которые за свой Period уже заполнились бы целиком
+func (s *MemoryStore) purge(now time.Time) {
+	if now.Sub(s.lastPurge) < time.Minute {
+		return
+	}
+	s.lastPurge = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updateAt) > b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
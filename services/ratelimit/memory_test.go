@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// rewind сдвигает бакет в прошлое, будто с последнего запроса прошло d
+func rewind(s *MemoryStore, key string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buckets[key].updateAt = s.buckets[key].updateAt.Add(-d)
+}
+
+func allow(t *testing.T, s *MemoryStore, key string, limit Limit) (bool, time.Duration) {
+	t.Helper()
+
+	ok, retryAfter, err := s.Allow(context.Background(), key, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ok, retryAfter
+}
+
+func TestMemoryStoreBurst(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Burst: 3, Period: time.Minute}
+
+	for i := 0; i < limit.Burst; i++ {
+		if ok, _ := allow(t, s, "ip", limit); !ok {
+			t.Fatalf("request %d of burst denied", i+1)
+		}
+	}
+
+	ok, retryAfter := allow(t, s, "ip", limit)
+	if ok {
+		t.Fatal("request over burst allowed")
+	}
+	// * Один токен восстанавливается за Period/Burst
+	if retryAfter <= 19*time.Second || retryAfter > 20*time.Second {
+		t.Errorf("retryAfter = %s, want about 20s", retryAfter)
+	}
+
+	// * Другой ключ — другой бакет
+	if ok, _ := allow(t, s, "other-ip", limit); !ok {
+		t.Error("request with another key denied")
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Burst: 2, Period: time.Minute}
+
+	allow(t, s, "ip", limit)
+	allow(t, s, "ip", limit)
+
+	// * Прошло полинтервала: токена еще нет, ждать осталось вторую половину
+	rewind(s, "ip", 15*time.Second)
+	ok, retryAfter := allow(t, s, "ip", limit)
+	if ok {
+		t.Fatal("request allowed before a token was refilled")
+	}
+	if retryAfter <= 14*time.Second || retryAfter > 15*time.Second {
+		t.Errorf("retryAfter = %s, want about 15s", retryAfter)
+	}
+
+	rewind(s, "ip", 15*time.Second)
+	if ok, _ := allow(t, s, "ip", limit); !ok {
+		t.Fatal("request denied after a token was refilled")
+	}
+	if ok, _ := allow(t, s, "ip", limit); ok {
+		t.Fatal("refill gave more than one token")
+	}
+
+	// * За долгий простой бакет наполняется только до Burst
+	rewind(s, "ip", time.Hour)
+	for i := 0; i < limit.Burst; i++ {
+		if ok, _ := allow(t, s, "ip", limit); !ok {
+			t.Fatalf("request %d after idle denied", i+1)
+		}
+	}
+	if ok, _ := allow(t, s, "ip", limit); ok {
+		t.Error("bucket refilled over burst")
+	}
+}
+
+func TestMemoryStoreDisabledLimit(t *testing.T) {
+	s := NewMemoryStore()
+
+	for _, limit := range []Limit{{Burst: 0, Period: time.Minute}, {Burst: 5, Period: 0}} {
+		for i := 0; i < 10; i++ {
+			if ok, retryAfter := allow(t, s, "ip", limit); !ok || retryAfter != 0 {
+				t.Fatalf("limit %+v: request %d denied", limit, i+1)
+			}
+		}
+	}
+}
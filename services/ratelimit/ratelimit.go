@@ -0,0 +1,24 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit — token bucket: Burst запросов подряд, дальше по одному раз в Period/Burst
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// interval — за сколько восстанавливается один токен
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Burst)
+}
+
+// Store хранит бакеты. Memory годится для одного инстанса,
+// при нескольких инстансах нужна общая реализация поверх Redis и т.п.
+type Store interface {
+	// Allow забирает токен из бакета key. Если токенов нет, возвращает через сколько можно повторить.
+	Allow(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+}
@@ -0,0 +1,102 @@
+package totp_test
+
+import (
+	"testing"
+	"time"
+
+	"health/services/totp"
+)
+
+// * Секрет из RFC 6238 — ascii "12345678901234567890" в base32, коды из приложения B урезаны до 6 цифр
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := totp.Code(rfcSecret, totp.Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != tt.code {
+			t.Errorf("Code at T=%d = %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestCodeLowercaseSecret(t *testing.T) {
+	code, err := totp.Code("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", totp.Step(time.Unix(59, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "287082" {
+		t.Errorf("Code with lowercase secret = %s, want 287082", code)
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := totp.Step(now)
+
+	tests := []struct {
+		name  string
+		shift int64
+		skew  int64
+		ok    bool
+	}{
+		{"current step", 0, 0, true},
+		{"previous step without skew", -1, 0, false},
+		{"previous step", -1, 1, true},
+		{"next step", 1, 1, true},
+		{"two steps back", -2, 1, false},
+		{"two steps ahead", 2, 1, false},
+		{"two steps back with skew 2", -2, 2, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := totp.Code(rfcSecret, current+tt.shift)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			step, ok := totp.Validate(rfcSecret, code, now, tt.skew)
+			if ok != tt.ok {
+				t.Fatalf("Validate ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && step != current+tt.shift {
+				t.Errorf("Validate step = %d, want %d", step, current+tt.shift)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsWrongCode(t *testing.T) {
+	if _, ok := totp.Validate(rfcSecret, "000000", time.Unix(59, 0), 1); ok {
+		t.Error("Validate accepted a wrong code")
+	}
+	if _, ok := totp.Validate("not base32!", "287082", time.Unix(59, 0), 1); ok {
+		t.Error("Validate accepted a code for a broken secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := totp.Code(secret, 1); err != nil {
+		t.Errorf("generated secret %q is not valid base32: %v", secret, err)
+	}
+}
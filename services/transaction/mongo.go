@@ -0,0 +1,67 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrMongoTransactionsUnsupported = errors.New("mongo transactions need a replica set or a sharded cluster")
+
+// MongoManager — транзакции поверх mongo.Session, нужен replica set или шардированный кластер.
+// Драйвер сам достает сессию из mongo.SessionContext, поэтому репозитории менять не нужно.
+type MongoManager struct {
+	client *mongo.Client
+}
+
+func NewMongoManager(client *mongo.Client) *MongoManager {
+	return &MongoManager{
+		client: client,
+	}
+}
+
+func (m *MongoManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := m.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// * WithTransaction сам коммитит, откатывает при ошибке и повторяет при TransientTransactionError
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+
+	return err
+}
+
+// CheckMongoSupport проверяет, что сервер умеет транзакции: одиночный mongod их не поддерживает,
+// и без этой проверки ошибка всплыла бы только на первой регистрации
+func CheckMongoSupport(ctx context.Context, db *mongo.Database) error {
+	var res struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+
+	// * isMaster, а не hello: hello нет у серверов старше 4.4
+	if err := db.RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&res); err != nil {
+		return err
+	}
+
+	if res.SetName == "" && res.Msg != "isdbgrid" {
+		return ErrMongoTransactionsUnsupported
+	}
+
+	return nil
+}
+
+// NewManager выбирает реализацию по конфигу db.transactions
+func NewManager(db *mongo.Database, enabled bool) Manager {
+	if !enabled {
+		return NewNoopManager()
+	}
+
+	return NewMongoManager(db.Client())
+}
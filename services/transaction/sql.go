@@ -0,0 +1,55 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+)
+
+type sqlTxKey struct{}
+
+// SQLManager — транзакции поверх database/sql. Открытая транзакция кладется в ctx,
+// репозитории берут ее через SQLExecutor.
+type SQLManager struct {
+	db *sql.DB
+}
+
+func NewSQLManager(db *sql.DB) *SQLManager {
+	return &SQLManager{
+		db: db,
+	}
+}
+
+func (m *SQLManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// * Вложенный вызов выполняется в уже открытой транзакции
+	if _, ok := ctx.Value(sqlTxKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, sqlTxKey{}, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Executor — общее у *sql.DB и *sql.Tx
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// SQLExecutor возвращает транзакцию из ctx, если репозиторий вызван внутри WithTransaction, иначе db
+func SQLExecutor(ctx context.Context, db *sql.DB) Executor {
+	if tx, ok := ctx.Value(sqlTxKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return db
+}
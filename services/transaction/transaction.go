@@ -0,0 +1,21 @@
+package transaction
+
+import "context"
+
+// Manager выполняет fn атомарно: либо все изменения внутри fn применяются, либо ни одно.
+// Репозитории участвуют в транзакции через ctx, поэтому fn должна передавать свой ctx дальше в каждый вызов.
+// fn может вызываться повторно при временных ошибках базы, побочные эффекты (письма, кеш) делаем после коммита.
+type Manager interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NoopManager просто вызывает fn, для баз без поддержки транзакций (одиночный mongod без replica set)
+type NoopManager struct{}
+
+func NewNoopManager() *NoopManager {
+	return &NoopManager{}
+}
+
+func (m *NoopManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
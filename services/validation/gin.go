@@ -0,0 +1,59 @@
+package validation
+
+import (
+	"health/shared/types"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator"
+)
+
+// ginValidator подключает общий валидатор к binding gin: ShouldBindJSON/ShouldBindQuery
+// проверяют validate теги сразу после разбора
+type ginValidator struct{}
+
+func init() {
+	binding.Validator = ginValidator{}
+}
+
+func (ginValidator) ValidateStruct(obj interface{}) error {
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return Validator().Struct(obj)
+}
+
+func (ginValidator) Engine() interface{} {
+	return Validator()
+}
+
+// BindJSON разбирает и валидирует тело запроса. При ошибке кладет ее в c.Error и возвращает false.
+func BindJSON(c *gin.Context, obj interface{}, tag string) bool {
+	return bind(c, obj, binding.JSON, tag)
+}
+
+// BindQuery — то же для query параметров
+func BindQuery(c *gin.Context, obj interface{}, tag string) bool {
+	return bind(c, obj, binding.Query, tag)
+}
+
+func bind(c *gin.Context, obj interface{}, b binding.Binding, tag string) bool {
+	err := c.ShouldBindWith(obj, b)
+	if err == nil {
+		return true
+	}
+
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		c.Error(toError(validationErrors, tag))
+	} else {
+		c.Error(types.InvalidInput(err, tag))
+	}
+
+	return false
+}
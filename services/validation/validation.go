@@ -0,0 +1,201 @@
+package validation
+
+import (
+	"health/models"
+	"health/services/i18n"
+	"health/services/iin"
+	"health/shared/errs"
+	"health/shared/types"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator"
+)
+
+var (
+	once     sync.Once
+	validate *validator.Validate
+)
+
+// Validator — общий валидатор, правила регистрируются один раз за процесс
+func Validator() *validator.Validate {
+	once.Do(func() {
+		validate = validator.New()
+
+		// * В ошибках пути по json/form именам полей, как их шлет клиент
+		validate.RegisterTagNameFunc(fieldName)
+
+		rules := map[string]validator.Func{
+			"IIN_custom_validation":      validateIIN,
+			"birthday_custom_validation": validateBirthday,
+			"gender_custom_validation":   validateGender,
+			"iin_birthday":               validateIINBirthday,
+			"iin_gender":                 validateIINGender,
+			"permission":                 validatePermission,
+			"locale":                     validateLocale,
+		}
+		for tag, fn := range rules {
+			if err := validate.RegisterValidation(tag, fn); err != nil {
+				panic(err)
+			}
+		}
+	})
+
+	return validate
+}
+
+func toError(validationErrors validator.ValidationErrors, tag string) *types.Error {
+	fields := make([]types.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		field := types.FieldError{
+			Field: fieldPath(fe),
+			Rule:  fe.Tag(),
+			Param: param(fe),
+			Key:   messageKey(fe),
+		}
+		// * Текст на языке по умолчанию, на язык запроса его переводит обработчик ошибок
+		field.Message = i18n.FieldMessage(i18n.Default(), field)
+
+		fields = append(fields, field)
+	}
+
+	// * Верхнеуровневые Message/Field — первая ошибка, для клиентов, которые смотрят только на них
+	return &types.Error{
+		Code:    errs.Validation,
+		Message: fields[0].Message,
+		Field:   fields[0].Field,
+		Tag:     tag,
+		Fields:  fields,
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+// fieldPath — путь без имени корневой структуры: address.city, а не UpdateProfileInput.address.city
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+
+	return namespace
+}
+
+// messageKey — ключ перевода сообщения, у min/max для строк отдельный текст про длину
+func messageKey(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "min", "max":
+		if fe.Kind() == reflect.String {
+			return "validation." + fe.Tag() + ".string"
+		}
+		return "validation." + fe.Tag()
+	case "number":
+		return "validation.numeric"
+	}
+
+	key := "validation." + fe.Tag()
+	if _, ok := i18n.Lookup(i18n.EN, key); !ok {
+		return "validation.invalid"
+	}
+
+	return key
+}
+
+// param — для правил, ссылающихся на другое поле, отдаем его json имя, а не имя в Go
+func param(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "eqfield", "required_without":
+		return lowerFirst(fe.Param())
+	}
+
+	return fe.Param()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+
+	return string(runes)
+}
+
+func validateIIN(fl validator.FieldLevel) bool {
+	_, err := iin.Parse(fl.Field().String())
+
+	return err == nil
+}
+
+// validateIINBirthday сверяет дату с ИИН из поля param. Неверный ИИН здесь не ошибка,
+// о нем уже сообщит IIN_custom_validation.
+func validateIINBirthday(fl validator.FieldLevel) bool {
+	info, ok := parseIINParam(fl)
+	if !ok {
+		return true
+	}
+
+	birthday, ok := fl.Field().Interface().(time.Time)
+
+	return ok && info.SameDate(birthday)
+}
+
+// validateIINGender — в ИИН кодируется только мужской или женский пол, остальные не сверяем
+func validateIINGender(fl validator.FieldLevel) bool {
+	info, ok := parseIINParam(fl)
+	if !ok {
+		return true
+	}
+
+	gender := models.Gender(fl.Field().Int())
+	if gender != models.Male && gender != models.Female {
+		return true
+	}
+
+	return gender == info.Gender
+}
+
+func parseIINParam(fl validator.FieldLevel) (*iin.Info, bool) {
+	field, kind, found := fl.GetStructFieldOK()
+	if !found || kind != reflect.String {
+		return nil, false
+	}
+
+	info, err := iin.Parse(field.String())
+
+	return info, err == nil
+}
+
+func validateBirthday(fl validator.FieldLevel) bool {
+	age := time.Since(fl.Field().Interface().(time.Time)).Hours() / 24 / 365.25
+
+	return age > 0 && age < 130
+}
+
+func validateGender(fl validator.FieldLevel) bool {
+	return models.Gender(fl.Field().Int()).String() != "unknown"
+}
+
+func validatePermission(fl validator.FieldLevel) bool {
+	return models.Permission(fl.Field().String()).IsKnown()
+}
+
+// validateLocale — только точный код поддерживаемого языка, в профиле храним его как есть
+func validateLocale(fl validator.FieldLevel) bool {
+	locale, ok := i18n.Parse(fl.Field().String())
+
+	return ok && string(locale) == fl.Field().String()
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type item[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Cache — потокобезопасный in-memory кеш с TTL на каждую запись.
+type Cache[K comparable, V any] struct {
+	mu        sync.RWMutex
+	ttl       time.Duration
+	items     map[K]item[V]
+	lastPurge time.Time
+}
+
+func New[K comparable, V any](ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		ttl:       ttl,
+		items:     make(map[K]item[V]),
+		lastPurge: time.Now(),
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	it, ok := c.items[key]
+	if !ok || time.Now().After(it.expiresAt) {
+		var defValue V
+		return defValue, false
+	}
+
+	return it.value, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.items[key] = item[V]{
+		value:     value,
+		expiresAt: now.Add(ttl),
+	}
+
+	// * Чистим протухшие записи не чаще раза в ttl, чтобы кеш не рос бесконечно
+	if now.Sub(c.lastPurge) > c.ttl {
+		for k, it := range c.items {
+			if now.After(it.expiresAt) {
+				delete(c.items, k)
+			}
+		}
+		c.lastPurge = now
+	}
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, key)
+}
+
+// Clear удаляет все записи
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[K]item[V])
+}
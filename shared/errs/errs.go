@@ -0,0 +1,155 @@
+package errs
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+)
+
+// ErrNotFound — записи нет. Репозитории всех драйверов возвращают ее вместо
+// mongo.ErrNoDocuments и sql.ErrNoRows, usecase проверяют ее через errors.Is.
+var ErrNotFound = errors.New("not found")
+
+// Code — стабильный машиночитаемый код ошибки. Фронт и другие сервисы завязываются на него,
+// поэтому существующие коды не переименовываем, только добавляем новые.
+type Code string
+
+const (
+	// * Общие
+	Internal     Code = "internal"
+	InvalidInput Code = "invalid_input"
+	Validation   Code = "validation"
+
+	// * auth
+	AuthInvalidCredentials      Code = "auth.invalid_credentials"
+	AuthUnauthorized            Code = "auth.unauthorized"
+	AuthUserNotFound            Code = "auth.user_not_found"
+	AuthUserExists              Code = "auth.user_exists"
+	AuthIINExists               Code = "auth.iin_exists"
+	AuthVerifyCodeInvalid       Code = "auth.verify_code_invalid"
+	AuthVerifyCodeExpired       Code = "auth.verify_code_expired"
+	AuthVerifyCodeTooManyTries  Code = "auth.verify_code_too_many_attempts"
+	AuthVerifyCodeLocked        Code = "auth.verify_code_locked"
+	AuthVerifyCodeResendTooSoon Code = "auth.verify_code_resend_too_soon"
+	AuthAccessTokenInvalid      Code = "auth.access_token_invalid"
+	AuthAccessTokenExpired      Code = "auth.access_token_expired"
+	AuthAccessTokenRevoked      Code = "auth.access_token_revoked"
+	AuthRefreshTokenInvalid     Code = "auth.refresh_token_invalid"
+	AuthRefreshTokenExpired     Code = "auth.refresh_token_expired"
+	AuthRefreshTokenReused      Code = "auth.refresh_token_reused"
+	AuthResetTokenInvalid       Code = "auth.reset_token_invalid"
+	AuthEmailIsSame             Code = "auth.email_is_same"
+	AuthEmailChangeNotRequested Code = "auth.email_change_not_requested"
+	AuthUserSuspended           Code = "auth.user_suspended"
+	AuthAccountLocked           Code = "auth.account_locked"
+	AuthTooManyRequests         Code = "auth.too_many_requests"
+	AuthPasswordSignInDisabled  Code = "auth.password_sign_in_disabled"
+	AuthTwoFactorEnabled        Code = "auth.two_factor_already_enabled"
+	AuthTwoFactorNotEnabled     Code = "auth.two_factor_not_enabled"
+	AuthTwoFactorNotEnrolled    Code = "auth.two_factor_not_enrolled"
+	AuthTwoFactorCodeInvalid    Code = "auth.two_factor_code_invalid"
+	AuthMFATokenInvalid         Code = "auth.mfa_token_invalid"
+	AuthRoleExists              Code = "auth.role_exists"
+	AuthUserRoleNotFound        Code = "auth.user_role_not_found"
+	AuthUserRoleDeleteFailed    Code = "auth.user_role_delete_failed"
+	AuthUserUpdateFailed        Code = "auth.user_update_failed"
+
+	// * role
+	RoleNotFound   Code = "role.not_found"
+	RoleNameExists Code = "role.name_exists"
+	RoleInUse      Code = "role.in_use"
+	RoleIsSystem   Code = "role.system"
+	RoleForbidden  Code = "role.forbidden"
+
+	// * user-role
+	UserRoleRoleNotFound   Code = "user_role.role_not_found"
+	UserRoleExists         Code = "user_role.exists"
+	UserRoleNotAssigned    Code = "user_role.not_assigned"
+	UserRoleDefaultRole    Code = "user_role.default_role"
+	UserRoleNotFound       Code = "user_role.not_found"
+	UserRoleAlreadyDecided Code = "user_role.already_decided"
+	UserRoleReasonRequired Code = "user_role.reason_required"
+	UserRoleCantDecideOwn  Code = "user_role.cant_decide_own"
+
+	// * admin
+	AdminUserNotFound   Code = "admin.user_not_found"
+	AdminCantManageSelf Code = "admin.cant_manage_self"
+)
+
+// statuses — HTTP статус для каждого кода, неизвестный код считается внутренней ошибкой
+var statuses = map[Code]int{
+	InvalidInput: http.StatusBadRequest,
+
+	AuthInvalidCredentials:  http.StatusUnauthorized,
+	AuthUnauthorized:        http.StatusUnauthorized,
+	AuthAccessTokenInvalid:  http.StatusUnauthorized,
+	AuthAccessTokenExpired:  http.StatusUnauthorized,
+	AuthAccessTokenRevoked:  http.StatusUnauthorized,
+	AuthRefreshTokenInvalid: http.StatusUnauthorized,
+	AuthRefreshTokenExpired: http.StatusUnauthorized,
+	AuthRefreshTokenReused:  http.StatusUnauthorized,
+	AuthMFATokenInvalid:     http.StatusUnauthorized,
+
+	AuthUserSuspended:          http.StatusForbidden,
+	AuthPasswordSignInDisabled: http.StatusForbidden,
+	RoleForbidden:              http.StatusForbidden,
+	UserRoleDefaultRole:        http.StatusForbidden,
+	UserRoleCantDecideOwn:      http.StatusForbidden,
+	AdminCantManageSelf:        http.StatusForbidden,
+
+	AuthUserNotFound:     http.StatusNotFound,
+	AuthUserRoleNotFound: http.StatusNotFound,
+	RoleNotFound:         http.StatusNotFound,
+	UserRoleRoleNotFound: http.StatusNotFound,
+	UserRoleNotAssigned:  http.StatusNotFound,
+	UserRoleNotFound:     http.StatusNotFound,
+	AdminUserNotFound:    http.StatusNotFound,
+
+	AuthUserExists:              http.StatusConflict,
+	AuthIINExists:               http.StatusConflict,
+	AuthEmailChangeNotRequested: http.StatusConflict,
+	AuthTwoFactorEnabled:        http.StatusConflict,
+	AuthTwoFactorNotEnabled:     http.StatusConflict,
+	AuthTwoFactorNotEnrolled:    http.StatusConflict,
+	AuthRoleExists:              http.StatusConflict,
+	RoleNameExists:              http.StatusConflict,
+	RoleInUse:                   http.StatusConflict,
+	RoleIsSystem:                http.StatusConflict,
+	UserRoleExists:              http.StatusConflict,
+	UserRoleAlreadyDecided:      http.StatusConflict,
+
+	Validation:               http.StatusUnprocessableEntity,
+	AuthVerifyCodeInvalid:    http.StatusUnprocessableEntity,
+	AuthVerifyCodeExpired:    http.StatusUnprocessableEntity,
+	AuthResetTokenInvalid:    http.StatusUnprocessableEntity,
+	AuthEmailIsSame:          http.StatusUnprocessableEntity,
+	AuthTwoFactorCodeInvalid: http.StatusUnprocessableEntity,
+	UserRoleReasonRequired:   http.StatusUnprocessableEntity,
+
+	AuthVerifyCodeTooManyTries:  http.StatusTooManyRequests,
+	AuthVerifyCodeLocked:        http.StatusTooManyRequests,
+	AuthVerifyCodeResendTooSoon: http.StatusTooManyRequests,
+	AuthAccountLocked:           http.StatusTooManyRequests,
+	AuthTooManyRequests:         http.StatusTooManyRequests,
+}
+
+// Status — HTTP статус ответа для кода
+func Status(code Code) int {
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// Codes — все коды каталога, например для переводов и документации
+func Codes() []Code {
+	codes := make([]Code, 0, len(statuses)+1)
+	codes = append(codes, Internal)
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	return codes
+}
@@ -1,17 +1,95 @@
 package types
 
+import (
+	"health/shared/errs"
+	"math"
+	"time"
+)
+
 type Error struct {
-	Message string `json:"message"`
+	Code    errs.Code `json:"code"`
+	Message string    `json:"message"`
+	Field   string    `json:"field"`
+	Tag     string    `json:"tag"`
+	// RetryAfter — через сколько секунд можно повторить, для ответов 429
+	RetryAfter int `json:"retryAfter,omitempty"`
+	// Fields — все ошибки валидации сразу, Message и Field выше повторяют первую из них
+	Fields []FieldError `json:"fields,omitempty"`
+
+	// Cause — исходная ошибка, в ответ не попадает, только в лог
+	Cause error `json:"-"`
+}
+
+// FieldError — ошибка одного поля, Field — json путь, например address.city
+type FieldError struct {
 	Field   string `json:"field"`
-	Tag     string `json:"tag"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+
+	// Key — ключ перевода Message, по нему сообщение переводится на язык запроса
+	Key string `json:"-"`
+}
+
+var (
+	ErrInternal = Error{
+		Code:    errs.Internal,
+		Message: "Internal server error",
+		Field:   "server",
+		Tag:     "server",
+	}
+)
+
+func (e *Error) Error() string {
+	if e.Message == "" && e.Cause != nil {
+		return e.Cause.Error()
+	}
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Wrap возвращает копию ошибки с причиной, сама ошибка из каталога не меняется
+func (e Error) Wrap(cause error) *Error {
+	e.Cause = cause
+
+	return &e
+}
+
+// WithRetryAfter возвращает копию ошибки, после которой запрос можно повторить через d
+func (e Error) WithRetryAfter(d time.Duration) *Error {
+	e.RetryAfter = int(math.Ceil(d.Seconds()))
+	if e.RetryAfter < 1 {
+		e.RetryAfter = 1
+	}
+
+	return &e
+}
+
+// InvalidInput — тело или query не разбираются в структуру входа
+func InvalidInput(cause error, tag string) *Error {
+	return &Error{
+		Code:    errs.InvalidInput,
+		Message: cause.Error(),
+		Field:   "input data",
+		Tag:     tag,
+		Cause:   cause,
+	}
 }
 
 type GoodResponse struct {
-	Code int                    `json:"code"`
-	Data map[string]interface{} `json:"data"`
+	Code int `json:"code"`
+	// Data — структура ответа роута из пакета его хендлера, по ней же строится OpenAPI схема
+	Data interface{} `json:"data"`
 }
 
 type BadResponse struct {
 	Code  int    `json:"code"`
 	Error *Error `json:"error"`
-}
\ No newline at end of file
+}
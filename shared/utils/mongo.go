@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"errors"
+	"health/shared/errs"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const duplicateKeyCode = 11000
+
+// IsDuplicateKeyError — запись нарушила уникальный индекс
+func IsDuplicateKeyError(err error) bool {
+	var writeException mongo.WriteException
+	if errors.As(err, &writeException) {
+		for _, writeErr := range writeException.WriteErrors {
+			if writeErr.Code == duplicateKeyCode {
+				return true
+			}
+		}
+	}
+
+	// * внутри транзакции драйвер может вернуть ошибку команды
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) {
+		return commandErr.Code == duplicateKeyCode
+	}
+
+	return false
+}
+
+// MongoNotFound переводит mongo.ErrNoDocuments в общую errs.ErrNotFound
+func MongoNotFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return errs.ErrNotFound
+	}
+
+	return err
+}
+
+// IsDuplicateKeyOn — запись нарушила именно уникальный индекс index
+func IsDuplicateKeyOn(err error, index string) bool {
+	return IsDuplicateKeyError(err) && strings.Contains(err.Error(), "index: "+index+" ")
+}
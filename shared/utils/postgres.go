@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"database/sql"
+	"errors"
+	"health/shared/errs"
+
+	"github.com/lib/pq"
+)
+
+const uniqueViolationCode = "23505"
+
+// IsUniqueViolation — запись нарушила уникальный индекс postgres
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == uniqueViolationCode
+	}
+
+	return false
+}
+
+// SQLNotFound переводит sql.ErrNoRows в общую errs.ErrNotFound
+func SQLNotFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return errs.ErrNotFound
+	}
+
+	return err
+}
+
+// IsUniqueViolationOn — запись нарушила именно уникальный индекс constraint
+func IsUniqueViolationOn(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == uniqueViolationCode && pqErr.Constraint == constraint
+	}
+
+	return false
+}
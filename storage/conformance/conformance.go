@@ -0,0 +1,80 @@
+// Package conformance — общие проверки хранилищ: mongo, postgres и memory должны вести себя одинаково.
+// Проверки создают свои записи со случайными email и именами и удаляют их за собой,
+// но гонять их стоит на отдельной базе, а не на боевой. Пакет собирается в бинарь ради `storage check`,
+// поэтому testing сюда не тянем: подтесты из Checks собирает storage/storage_test.go.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"health/shared/errs"
+	"health/storage"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Check — одна проверка хранилища
+type Check struct {
+	Name string
+	Run  func(ctx context.Context, s *storage.Storage) error
+}
+
+// Checks — все проверки по порядку
+var Checks = []Check{
+	{Name: "users", Run: checkUsers},
+	{Name: "users list", Run: checkListUsers},
+	{Name: "sign-in lock", Run: checkSignInLock},
+	{Name: "verify code attempts", Run: checkVerifyCodeAttempts},
+	{Name: "roles", Run: checkRoles},
+	{Name: "user roles", Run: checkUserRoles},
+	{Name: "sessions", Run: checkSessions},
+	{Name: "revocations", Run: checkRevocations},
+	{Name: "transactions", Run: checkTransactions},
+}
+
+func expect(ok bool, format string, args ...interface{}) error {
+	if ok {
+		return nil
+	}
+
+	return fmt.Errorf(format, args...)
+}
+
+func expectErrorIs(err error, target error, action string) error {
+	return expect(errors.Is(err, target), "%s: expected %v, got %v", action, target, err)
+}
+
+// expectNotFound — все драйверы отвечают на отсутствующую запись одной ошибкой errs.ErrNotFound
+func expectNotFound(err error, action string) error {
+	return expectErrorIs(err, errs.ErrNotFound, action)
+}
+
+func expectError(err error, action string) error {
+	return expect(err != nil, "%s: expected error, got nil", action)
+}
+
+// sameTime — монга хранит миллисекунды, postgres микросекунды
+func sameTime(a time.Time, b time.Time) bool {
+	diff := a.Sub(b)
+	if diff < 0 {
+		diff = -diff
+	}
+
+	return diff < time.Millisecond
+}
+
+func uniqueEmail(prefix string) string {
+	return fmt.Sprintf("%s-%s@conformance.test", prefix, primitive.NewObjectID().Hex())
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
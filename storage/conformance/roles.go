@@ -0,0 +1,225 @@
+package conformance
+
+import (
+	"context"
+	"health/models"
+	"health/routes/client/role"
+	"health/routes/client/userRole"
+	"health/storage"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func checkRoles(ctx context.Context, s *storage.Storage) error {
+	name := models.RoleName("conformance-" + primitive.NewObjectID().Hex())
+
+	roleEntity := &models.Role{
+		Name:        name,
+		Permissions: []models.Permission{models.PermissionProfileRead},
+	}
+	if err := s.Roles.CreateRole(ctx, roleEntity); err != nil {
+		return err
+	}
+	defer s.Roles.DeleteRole(ctx, roleEntity.ID) //nolint:errcheck
+
+	if err := expectErrorIs(s.Roles.CreateRole(ctx, &models.Role{Name: name}), role.ErrRoleNameIsTaken, "CreateRole with taken name"); err != nil {
+		return err
+	}
+
+	found, err := s.Roles.GetRoleByName(ctx, string(name))
+	if err != nil {
+		return err
+	}
+	err = firstError(
+		expect(found.ID == roleEntity.ID, "GetRoleByName: id %s, want %s", found.ID, roleEntity.ID),
+		expect(len(found.Permissions) == 1 && found.Permissions[0] == models.PermissionProfileRead, "GetRoleByName: permissions %v", found.Permissions),
+	)
+	if err != nil {
+		return err
+	}
+
+	roles, err := s.Roles.GetRoleByIDs(ctx, []string{roleEntity.ID, primitive.NewObjectID().Hex()})
+	if err != nil {
+		return err
+	}
+	if err := expect(len(roles) == 1 && roles[0].ID == roleEntity.ID, "GetRoleByIDs: got %d roles", len(roles)); err != nil {
+		return err
+	}
+
+	roles, err = s.Roles.GetRoleByIDs(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := expect(len(roles) == 0, "GetRoleByIDs without ids: got %d roles", len(roles)); err != nil {
+		return err
+	}
+
+	found.Permissions = append(found.Permissions, models.PermissionProfileWrite)
+	found.IsDefault = true
+	if err := s.Roles.UpdateRole(ctx, found); err != nil {
+		return err
+	}
+
+	updated, err := s.Roles.GetRoleByID(ctx, roleEntity.ID)
+	if err != nil {
+		return err
+	}
+	if err := expect(updated.IsDefault && len(updated.Permissions) == 2, "UpdateRole: isDefault %v, permissions %v", updated.IsDefault, updated.Permissions); err != nil {
+		return err
+	}
+
+	// * Переименование в занятое имя упирается в уникальность
+	other := &models.Role{Name: models.RoleName("conformance-" + primitive.NewObjectID().Hex())}
+	if err := s.Roles.CreateRole(ctx, other); err != nil {
+		return err
+	}
+	defer s.Roles.DeleteRole(ctx, other.ID) //nolint:errcheck
+
+	other.Name = name
+	if err := expectErrorIs(s.Roles.UpdateRole(ctx, other), role.ErrRoleNameIsTaken, "UpdateRole with taken name"); err != nil {
+		return err
+	}
+
+	missing := &models.Role{ID: primitive.NewObjectID().Hex(), Name: models.RoleName(primitive.NewObjectID().Hex())}
+	err = firstError(
+		expectNotFound(s.Roles.UpdateRole(ctx, missing), "UpdateRole of missing role"),
+		expectNotFound(s.Roles.DeleteRole(ctx, missing.ID), "DeleteRole of missing role"),
+		expectError(getRoleErr(s.Roles.GetRoleByID(ctx, "not-an-id")), "GetRoleByID with invalid id"),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Roles.DeleteRole(ctx, roleEntity.ID); err != nil {
+		return err
+	}
+
+	return expectNotFound(getRoleErr(s.Roles.GetRoleByID(ctx, roleEntity.ID)), "GetRoleByID after DeleteRole")
+}
+
+func checkUserRoles(ctx context.Context, s *storage.Storage) error {
+	userID := primitive.NewObjectID().Hex()
+	roleID := primitive.NewObjectID().Hex()
+	adminID := primitive.NewObjectID().Hex()
+	defer s.UserRoles.DeleteUserRolesByUserID(ctx, userID) //nolint:errcheck
+
+	preID := primitive.NewObjectID().Hex()
+	pending := &models.UserRole{
+		ID:     preID,
+		UserID: userID,
+		RoleID: roleID,
+		Status: models.UserRoleStatusPending,
+	}
+	if err := s.UserRoles.CreateUserRole(ctx, pending); err != nil {
+		return err
+	}
+	if err := expect(pending.ID == preID, "CreateUserRole: pre-generated id %s replaced with %s", preID, pending.ID); err != nil {
+		return err
+	}
+
+	approved := &models.UserRole{
+		UserID: userID,
+		RoleID: primitive.NewObjectID().Hex(),
+		Status: models.UserRoleStatusApproved,
+	}
+	if err := s.UserRoles.CreateUserRole(ctx, approved); err != nil {
+		return err
+	}
+
+	found, err := s.UserRoles.GetUserRoleByID(ctx, preID)
+	if err != nil {
+		return err
+	}
+	if err := expect(found.UserID == userID && found.RoleID == roleID && found.DecidedBy == "", "GetUserRoleByID: %+v", found); err != nil {
+		return err
+	}
+
+	userRoles, err := s.UserRoles.GetUserRoleByIDs(ctx, []string{preID, approved.ID, primitive.NewObjectID().Hex()})
+	if err != nil {
+		return err
+	}
+	if err := expect(len(userRoles) == 2, "GetUserRoleByIDs: got %d, want 2", len(userRoles)); err != nil {
+		return err
+	}
+
+	list, total, err := s.UserRoles.GetUserRoles(ctx, &userRole.ListFilter{
+		UserID: userID,
+		Status: models.UserRoleStatusPending,
+	})
+	if err != nil {
+		return err
+	}
+	if err := expect(total == 1 && len(list) == 1 && list[0].ID == preID, "GetUserRoles pending: total %d", total); err != nil {
+		return err
+	}
+
+	list, total, err = s.UserRoles.GetUserRoles(ctx, &userRole.ListFilter{UserID: userID, Skip: 1, Limit: 1})
+	if err != nil {
+		return err
+	}
+	if err := expect(total == 2 && len(list) == 1 && list[0].ID == approved.ID, "GetUserRoles page: total %d, len %d", total, len(list)); err != nil {
+		return err
+	}
+
+	count, err := s.UserRoles.CountUserRolesByRoleID(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if err := expect(count == 1, "CountUserRolesByRoleID: %d, want 1", count); err != nil {
+		return err
+	}
+
+	// * Решение принимается один раз
+	found.Status = models.UserRoleStatusApproved
+	found.DecidedBy = adminID
+	found.Reason = "ok"
+	decided, err := s.UserRoles.DecideUserRole(ctx, found)
+	if err != nil {
+		return err
+	}
+	if err := expect(decided, "DecideUserRole of pending request returned false"); err != nil {
+		return err
+	}
+
+	decided, err = s.UserRoles.DecideUserRole(ctx, found)
+	if err != nil {
+		return err
+	}
+	if err := expect(!decided, "DecideUserRole of decided request returned true"); err != nil {
+		return err
+	}
+
+	found, err = s.UserRoles.GetUserRoleByID(ctx, preID)
+	if err != nil {
+		return err
+	}
+	if err := expect(found.Status == models.UserRoleStatusApproved && found.DecidedBy == adminID && found.Reason == "ok", "DecideUserRole: %+v", found); err != nil {
+		return err
+	}
+
+	if err := s.UserRoles.DeleteUserRoleByID(ctx, preID); err != nil {
+		return err
+	}
+	if err := expectNotFound(getUserRoleErr(s.UserRoles.GetUserRoleByID(ctx, preID)), "GetUserRoleByID after DeleteUserRoleByID"); err != nil {
+		return err
+	}
+
+	if err := s.UserRoles.DeleteUserRolesByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	_, total, err = s.UserRoles.GetUserRoles(ctx, &userRole.ListFilter{UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	return expect(total == 0, "DeleteUserRolesByUserID left %d records", total)
+}
+
+func getRoleErr(_ *models.Role, err error) error {
+	return err
+}
+
+func getUserRoleErr(_ *models.UserRole, err error) error {
+	return err
+}
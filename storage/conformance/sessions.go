@@ -0,0 +1,137 @@
+package conformance
+
+import (
+	"context"
+	"health/models"
+	"health/storage"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func checkSessions(ctx context.Context, s *storage.Storage) error {
+	userID := primitive.NewObjectID().Hex()
+	familyID := primitive.NewObjectID().Hex()
+	defer s.Sessions.RevokeUserSessions(ctx, userID) //nolint:errcheck
+
+	session := &models.Session{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: "hash-" + primitive.NewObjectID().Hex(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := s.Sessions.CreateSession(ctx, session); err != nil {
+		return err
+	}
+
+	found, err := s.Sessions.GetSessionByTokenHash(ctx, session.TokenHash)
+	if err != nil {
+		return err
+	}
+	if err := expect(found.ID == session.ID && found.FamilyID == familyID, "GetSessionByTokenHash: %+v", found); err != nil {
+		return err
+	}
+
+	if err := s.Sessions.SetSessionAccessTokenID(ctx, familyID, "jti"); err != nil {
+		return err
+	}
+
+	// * Ротация проходит ровно один раз
+	rotated, err := s.Sessions.RotateSession(ctx, session.ID)
+	if err != nil {
+		return err
+	}
+	if err := expect(rotated, "RotateSession of fresh session returned false"); err != nil {
+		return err
+	}
+
+	rotated, err = s.Sessions.RotateSession(ctx, session.ID)
+	if err != nil {
+		return err
+	}
+	if err := expect(!rotated, "RotateSession of rotated session returned true"); err != nil {
+		return err
+	}
+
+	next := &models.Session{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: "hash-" + primitive.NewObjectID().Hex(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := s.Sessions.CreateSession(ctx, next); err != nil {
+		return err
+	}
+
+	sessions, err := s.Sessions.GetSessionsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := expect(len(sessions) == 2, "GetSessionsByUserID: got %d, want 2", len(sessions)); err != nil {
+		return err
+	}
+
+	for _, s := range sessions {
+		if s.ID == session.ID {
+			if err := expect(s.AccessTokenID == "jti" && s.Rotated, "SetSessionAccessTokenID/RotateSession: %+v", s); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.Sessions.RevokeSessionFamily(ctx, familyID); err != nil {
+		return err
+	}
+
+	found, err = s.Sessions.GetSessionByTokenHash(ctx, next.TokenHash)
+	if err != nil {
+		return err
+	}
+	if err := expect(found.Revoked, "RevokeSessionFamily: session not revoked"); err != nil {
+		return err
+	}
+
+	rotated, err = s.Sessions.RotateSession(ctx, next.ID)
+	if err != nil {
+		return err
+	}
+	if err := expect(!rotated, "RotateSession of revoked session returned true"); err != nil {
+		return err
+	}
+
+	_, err = s.Sessions.GetSessionByTokenHash(ctx, "missing-"+primitive.NewObjectID().Hex())
+
+	return expectNotFound(err, "GetSessionByTokenHash of missing session")
+}
+
+func checkRevocations(ctx context.Context, s *storage.Storage) error {
+	token := &models.RevokedToken{
+		TokenID:   primitive.NewObjectID().Hex(),
+		UserID:    primitive.NewObjectID().Hex(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	revoked, err := s.Revocations.IsTokenRevoked(ctx, token.TokenID)
+	if err != nil {
+		return err
+	}
+	if err := expect(!revoked, "IsTokenRevoked of fresh token returned true"); err != nil {
+		return err
+	}
+
+	if err := s.Revocations.RevokeToken(ctx, token); err != nil {
+		return err
+	}
+
+	// * Повторный отзыв — не ошибка
+	if err := s.Revocations.RevokeToken(ctx, token); err != nil {
+		return err
+	}
+
+	revoked, err = s.Revocations.IsTokenRevoked(ctx, token.TokenID)
+	if err != nil {
+		return err
+	}
+
+	return expect(revoked, "IsTokenRevoked of revoked token returned false")
+}
@@ -0,0 +1,51 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"health/models"
+	"health/services/transaction"
+	"health/storage"
+)
+
+var errRollback = errors.New("rollback")
+
+func checkTransactions(ctx context.Context, s *storage.Storage) error {
+	committed := &models.User{Email: uniqueEmail("tx-commit")}
+
+	err := s.Transactions.WithTransaction(ctx, func(ctx context.Context) error {
+		return s.Users.CreateUser(ctx, committed)
+	})
+	if err != nil {
+		return err
+	}
+	defer s.Users.DeleteUser(ctx, committed.ID) //nolint:errcheck
+
+	if _, err := s.Users.GetUserById(ctx, committed.ID); err != nil {
+		return err
+	}
+
+	// * Без транзакций откатывать нечего, проверяем только коммит
+	if _, ok := s.Transactions.(*transaction.NoopManager); ok {
+		return nil
+	}
+
+	rolledBack := &models.User{Email: uniqueEmail("tx-rollback")}
+	err = s.Transactions.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.Users.CreateUser(ctx, rolledBack); err != nil {
+			return err
+		}
+
+		return errRollback
+	})
+	if err := expectErrorIs(err, errRollback, "WithTransaction"); err != nil {
+		return err
+	}
+
+	_, err = s.Users.GetUserByEmail(ctx, rolledBack.Email)
+	if err == nil {
+		s.Users.DeleteUser(ctx, rolledBack.ID) //nolint:errcheck
+	}
+
+	return expectNotFound(err, "GetUserByEmail after rollback")
+}
@@ -0,0 +1,392 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"health/models"
+	"health/routes/client/auth"
+	"health/storage"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func checkUsers(ctx context.Context, s *storage.Storage) error {
+	id := primitive.NewObjectID().Hex()
+	user := &models.User{
+		ID:          id,
+		Email:       uniqueEmail("user"),
+		Password:    "hash",
+		UserRoleIDs: []string{primitive.NewObjectID().Hex()},
+		TwoFactor: models.TwoFactor{
+			Enabled:       true,
+			Secret:        "SECRET",
+			LastUsedStep:  42,
+			RecoveryCodes: []string{"a", "b"},
+		},
+		VerifyCode: models.VerifyCode{
+			Hash:      "code-hash",
+			ExpiresAt: time.Now().Add(time.Hour),
+			Attempts:  2,
+		},
+		Address: models.Address{City: "Almaty"},
+		Gender:  models.Female,
+	}
+
+	if err := s.Users.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	defer s.Users.DeleteUser(ctx, id) //nolint:errcheck
+
+	if err := expect(user.ID == id, "CreateUser: pre-generated id %s replaced with %s", id, user.ID); err != nil {
+		return err
+	}
+
+	found, err := s.Users.GetUserById(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = firstError(
+		expect(found.Email == user.Email, "GetUserById: email %q, want %q", found.Email, user.Email),
+		expect(len(found.UserRoleIDs) == 1 && found.UserRoleIDs[0] == user.UserRoleIDs[0], "GetUserById: userRoleIds %v", found.UserRoleIDs),
+		expect(found.TwoFactor.Enabled && found.TwoFactor.LastUsedStep == 42 && len(found.TwoFactor.RecoveryCodes) == 2, "GetUserById: twoFactor %+v", found.TwoFactor),
+		expect(found.VerifyCode.Hash == "code-hash" && found.VerifyCode.Attempts == 2, "GetUserById: verifyCode %+v", found.VerifyCode),
+		expect(sameTime(found.VerifyCode.ExpiresAt, user.VerifyCode.ExpiresAt), "GetUserById: verifyCode.expiresAt %s", found.VerifyCode.ExpiresAt),
+		expect(found.Address.City == "Almaty" && found.Gender == models.Female, "GetUserById: address %+v, gender %d", found.Address, found.Gender),
+		expect(found.SuspendedAt.IsZero(), "GetUserById: suspendedAt must stay zero, got %s", found.SuspendedAt),
+		expect(sameTime(found.CreatedAt, user.CreatedAt), "GetUserById: createdAt %s, want %s", found.CreatedAt, user.CreatedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.Users.GetUserByEmail(ctx, user.Email); err != nil {
+		return err
+	}
+
+	// * email уникальный и при создании, и при обновлении
+	duplicate := &models.User{Email: user.Email}
+	if err := expectErrorIs(s.Users.CreateUser(ctx, duplicate), auth.ErrEmailIsTaken, "CreateUser with taken email"); err != nil {
+		return err
+	}
+
+	other := &models.User{Email: uniqueEmail("other")}
+	if err := s.Users.CreateUser(ctx, other); err != nil {
+		return err
+	}
+	defer s.Users.DeleteUser(ctx, other.ID) //nolint:errcheck
+
+	other.Email = user.Email
+	if err := expectErrorIs(s.Users.UpdateUser(ctx, other), auth.ErrEmailIsTaken, "UpdateUser with taken email"); err != nil {
+		return err
+	}
+
+	// * ИИН уникален только заполненный, ведущий ноль сохраняется
+	iin := fmt.Sprintf("0%011d", time.Now().UnixNano()%1e11)
+	found.IIN = iin
+	found.Locale = "kk"
+	if err := s.Users.UpdateUser(ctx, found); err != nil {
+		return err
+	}
+
+	withIIN, err := s.Users.GetUserById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := expect(withIIN.IIN == iin, "UpdateUser: IIN %q, want %q", withIIN.IIN, iin); err != nil {
+		return err
+	}
+	if err := expect(withIIN.Locale == "kk", "UpdateUser: Locale %q, want %q", withIIN.Locale, "kk"); err != nil {
+		return err
+	}
+
+	other.Email = uniqueEmail("other")
+	other.IIN = iin
+	if err := expectErrorIs(s.Users.UpdateUser(ctx, other), auth.ErrIINIsTaken, "UpdateUser with taken IIN"); err != nil {
+		return err
+	}
+
+	// * Обновление меняет поля, но не created_at
+	found.Suspended = true
+	found.SuspendedAt = time.Now()
+	found.RolesVersion = 3
+	found.TwoFactor = models.TwoFactor{}
+	if err := s.Users.UpdateUser(ctx, found); err != nil {
+		return err
+	}
+
+	updated, err := s.Users.GetUserById(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = firstError(
+		expect(updated.Suspended && updated.RolesVersion == 3, "UpdateUser: suspended %v, rolesVersion %d", updated.Suspended, updated.RolesVersion),
+		expect(!updated.TwoFactor.Enabled && len(updated.TwoFactor.RecoveryCodes) == 0, "UpdateUser: twoFactor not cleared %+v", updated.TwoFactor),
+		expect(sameTime(updated.CreatedAt, user.CreatedAt), "UpdateUser: createdAt changed to %s", updated.CreatedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	// * Изменение полученного юзера без UpdateUser не должно попадать в хранилище
+	updated.Email = "changed@conformance.test"
+	again, err := s.Users.GetUserById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := expect(again.Email == user.Email, "GetUserById returned a shared instance"); err != nil {
+		return err
+	}
+
+	// * Обновление несуществующего юзера ничего не делает, как UpdateOne у монги
+	if err := s.Users.UpdateUser(ctx, &models.User{ID: primitive.NewObjectID().Hex(), Email: uniqueEmail("ghost")}); err != nil {
+		return err
+	}
+
+	err = firstError(
+		expectNotFound(getUserErr(s.Users.GetUserById(ctx, primitive.NewObjectID().Hex())), "GetUserById of missing user"),
+		expectError(getUserErr(s.Users.GetUserById(ctx, "not-an-id")), "GetUserById with invalid id"),
+		expectNotFound(getUserErr(s.Users.GetUserByEmail(ctx, uniqueEmail("missing"))), "GetUserByEmail of missing user"),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Users.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+
+	return expectNotFound(getUserErr(s.Users.GetUserById(ctx, id)), "GetUserById after DeleteUser")
+}
+
+func checkListUsers(ctx context.Context, s *storage.Storage) error {
+	marker := primitive.NewObjectID().Hex()
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		user := &models.User{
+			Email:    uniqueEmail("list-" + marker),
+			Verified: i > 0,
+		}
+		if err := s.Users.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		defer s.Users.DeleteUser(ctx, user.ID) //nolint:errcheck
+
+		ids = append(ids, user.ID)
+		// * разные created_at, чтобы порядок был определен
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	verified := true
+	users, total, err := s.Users.ListUsers(ctx, &auth.UserFilter{
+		Email:    "LIST-" + marker,
+		Verified: &verified,
+	})
+	if err != nil {
+		return err
+	}
+	if err := expect(total == 2 && len(users) == 2, "ListUsers by email and verified: total %d, len %d, want 2", total, len(users)); err != nil {
+		return err
+	}
+
+	users, total, err = s.Users.ListUsers(ctx, &auth.UserFilter{
+		IDs:      ids,
+		SortBy:   "created_at",
+		SortDesc: true,
+		Skip:     1,
+		Limit:    1,
+	})
+	if err != nil {
+		return err
+	}
+	err = firstError(
+		expect(total == 3, "ListUsers by ids: total %d, want 3", total),
+		expect(len(users) == 1 && users[0].ID == ids[1], "ListUsers page: got %v, want [%s]", userIDs(users), ids[1]),
+	)
+	if err != nil {
+		return err
+	}
+
+	users, total, err = s.Users.ListUsers(ctx, &auth.UserFilter{IDs: []string{}})
+	if err != nil {
+		return err
+	}
+
+	return expect(total == 0 && len(users) == 0, "ListUsers with empty ids: total %d, len %d", total, len(users))
+}
+
+// checkSignInLock — счетчик неверных паролей меняется атомарно и не задевает остальные поля юзера
+func checkSignInLock(ctx context.Context, s *storage.Storage) error {
+	user := &models.User{Email: uniqueEmail("lock"), Password: "old-hash"}
+	if err := s.Users.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	defer s.Users.DeleteUser(ctx, user.ID) //nolint:errcheck
+
+	// * Юзер прочитан до смены пароля, как в SignIn до сверки bcrypt
+	stale, err := s.Users.GetUserById(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	user.Password = "new-hash"
+	if err := s.Users.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+
+	const attempts = 10
+	lockedUntil := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	lockUntil := func(failedAttempts int) time.Time {
+		if failedAttempts < attempts {
+			return time.Time{}
+		}
+
+		return lockedUntil
+	}
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Users.RecordFailedSignIn(ctx, stale.ID, lockUntil); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	if err := <-errCh; err != nil {
+		return err
+	}
+
+	found, err := s.Users.GetUserById(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	err = firstError(
+		expect(found.SignInLock.FailedAttempts == attempts, "RecordFailedSignIn: failedAttempts %d, want %d", found.SignInLock.FailedAttempts, attempts),
+		expect(sameTime(found.SignInLock.LockedUntil, lockedUntil), "RecordFailedSignIn: lockedUntil %s, want %s", found.SignInLock.LockedUntil, lockedUntil),
+		expect(found.Password == "new-hash", "RecordFailedSignIn overwrote password with %q", found.Password),
+	)
+	if err != nil {
+		return err
+	}
+
+	// * Более короткий срок не сокращает уже выставленную блокировку
+	lock, err := s.Users.RecordFailedSignIn(ctx, user.ID, func(int) time.Time { return time.Now() })
+	if err != nil {
+		return err
+	}
+	if err := expect(sameTime(lock.LockedUntil, lockedUntil), "RecordFailedSignIn shortened lockedUntil to %s", lock.LockedUntil); err != nil {
+		return err
+	}
+
+	if err := s.Users.ResetSignInLock(ctx, user.ID); err != nil {
+		return err
+	}
+
+	found, err = s.Users.GetUserById(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := expect(found.SignInLock.FailedAttempts == 0 && found.SignInLock.LockedUntil.IsZero(), "ResetSignInLock: %+v", found.SignInLock); err != nil {
+		return err
+	}
+
+	_, err = s.Users.RecordFailedSignIn(ctx, primitive.NewObjectID().Hex(), lockUntil)
+
+	return expectNotFound(err, "RecordFailedSignIn of missing user")
+}
+
+// checkVerifyCodeAttempts — попытки ввода кода засчитываются атомарно и не больше лимита
+func checkVerifyCodeAttempts(ctx context.Context, s *storage.Storage) error {
+	const maxAttempts = 3
+
+	user := &models.User{
+		Email:      uniqueEmail("code"),
+		VerifyCode: models.VerifyCode{Hash: "code-hash", ExpiresAt: time.Now().Add(time.Hour)},
+		EmailChange: models.EmailChange{
+			Email: uniqueEmail("new"),
+			Code:  models.VerifyCode{Hash: "change-hash", ExpiresAt: time.Now().Add(time.Hour)},
+		},
+	}
+	if err := s.Users.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	defer s.Users.DeleteUser(ctx, user.ID) //nolint:errcheck
+
+	lockedUntil := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+
+	for _, field := range []auth.VerifyCodeField{auth.VerifyCodeEmail, auth.VerifyCodeEmailChange} {
+		hash := "code-hash"
+		if field == auth.VerifyCodeEmailChange {
+			hash = "change-hash"
+		}
+
+		attempt, err := s.Users.RecordVerifyCodeAttempt(ctx, user.ID, field, "other-hash", maxAttempts, lockedUntil)
+		if err != nil {
+			return err
+		}
+		if err := expect(attempt == 0, "RecordVerifyCodeAttempt(%d) with a replaced code: attempt %d, want 0", field, attempt); err != nil {
+			return err
+		}
+
+		// * Параллельных попыток больше лимита, засчитаться должны ровно maxAttempts
+		var wg sync.WaitGroup
+		recorded := make(chan int, maxAttempts*3)
+		errCh := make(chan error, maxAttempts*3)
+		for i := 0; i < maxAttempts*3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				attempt, err := s.Users.RecordVerifyCodeAttempt(ctx, user.ID, field, hash, maxAttempts, lockedUntil)
+				if err != nil {
+					errCh <- err
+					return
+				}
+				if attempt > 0 {
+					recorded <- attempt
+				}
+			}()
+		}
+		wg.Wait()
+		close(recorded)
+		close(errCh)
+		if err := <-errCh; err != nil {
+			return err
+		}
+		if err := expect(len(recorded) == maxAttempts, "RecordVerifyCodeAttempt(%d): recorded %d attempts, want %d", field, len(recorded), maxAttempts); err != nil {
+			return err
+		}
+	}
+
+	found, err := s.Users.GetUserById(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	return firstError(
+		expect(found.VerifyCode.Attempts == maxAttempts, "RecordVerifyCodeAttempt: verifyCode.attempts %d, want %d", found.VerifyCode.Attempts, maxAttempts),
+		expect(sameTime(found.VerifyCode.LockedUntil, lockedUntil), "RecordVerifyCodeAttempt: verifyCode.lockedUntil %s, want %s", found.VerifyCode.LockedUntil, lockedUntil),
+		expect(found.EmailChange.Code.Attempts == maxAttempts, "RecordVerifyCodeAttempt: emailChange.code.attempts %d, want %d", found.EmailChange.Code.Attempts, maxAttempts),
+		expect(sameTime(found.EmailChange.Code.LockedUntil, lockedUntil), "RecordVerifyCodeAttempt: emailChange.code.lockedUntil %s", found.EmailChange.Code.LockedUntil),
+		expect(found.EmailChange.Email == user.EmailChange.Email, "RecordVerifyCodeAttempt changed emailChange.email to %q", found.EmailChange.Email),
+	)
+}
+
+func getUserErr(_ *models.User, err error) error {
+	return err
+}
+
+func userIDs(users []*models.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	return ids
+}
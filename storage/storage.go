@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"database/sql"
+	"health/routes/client/auth"
+	authRepository "health/routes/client/auth/repository"
+	"health/routes/client/role"
+	roleRepository "health/routes/client/role/repository"
+	"health/routes/client/userRole"
+	userRoleRepository "health/routes/client/userRole/repository"
+	"health/services/transaction"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	DriverMongo    = "mongo"
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
+// Storage — все репозитории приложения. Создается один раз и общий для всех модулей,
+// иначе у in-memory хранилища у каждого модуля были бы свои данные.
+type Storage struct {
+	Users       auth.Repository
+	Sessions    auth.SessionRepository
+	Revocations auth.RevocationRepository
+	Roles       role.Repository
+	UserRoles   userRole.Repository
+
+	Transactions transaction.Manager
+}
+
+func NewMongoStorage(db *mongo.Database, transactions bool) *Storage {
+	return &Storage{
+		Users:       authRepository.NewRepository(db),
+		Sessions:    authRepository.NewSessionRepository(db),
+		Revocations: authRepository.NewRevocationRepository(db),
+		Roles:       roleRepository.NewRepository(db),
+		UserRoles:   userRoleRepository.NewRepository(db),
+
+		Transactions: transaction.NewManager(db, transactions),
+	}
+}
+
+// NewPostgresStorage — схему создают sql миграции из migrations/postgres
+func NewPostgresStorage(db *sql.DB) *Storage {
+	return &Storage{
+		Users:       authRepository.NewPostgresRepository(db),
+		Sessions:    authRepository.NewPostgresSessionRepository(db),
+		Revocations: authRepository.NewPostgresRevocationRepository(db),
+		Roles:       roleRepository.NewPostgresRepository(db),
+		UserRoles:   userRoleRepository.NewPostgresRepository(db),
+
+		Transactions: transaction.NewSQLManager(db),
+	}
+}
+
+// NewMemoryStorage — данные живут до перезапуска процесса, для локальной разработки без монги.
+// Транзакций нет: при ошибке посреди SignUp/UpdateProfile изменения не откатываются.
+func NewMemoryStorage() *Storage {
+	return &Storage{
+		Users:       authRepository.NewMemoryRepository(),
+		Sessions:    authRepository.NewMemorySessionRepository(),
+		Revocations: authRepository.NewMemoryRevocationRepository(),
+		Roles:       roleRepository.NewMemoryRepository(),
+		UserRoles:   userRoleRepository.NewMemoryRepository(),
+
+		Transactions: transaction.NewNoopManager(),
+	}
+}
@@ -0,0 +1,145 @@
+package storage_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"health/migrations"
+	"health/models"
+	"health/services/migration"
+	"health/shared/errs"
+	"health/storage"
+	"health/storage/conformance"
+
+	_ "github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// * Монга и postgres берутся из окружения, без них тесты драйверов пропускаются:
+// MONGO_URI=mongodb://localhost:27017 POSTGRES_DSN=postgres://localhost/health_test?sslmode=disable go test ./storage/
+
+// runConformance выполняет все проверки подтестами t, упавшая проверка не останавливает остальные
+func runConformance(t *testing.T, s *storage.Storage) {
+	for _, check := range conformance.Checks {
+		check := check
+		t.Run(check.Name, func(t *testing.T) {
+			if err := check.Run(context.Background(), s); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// expiredSessionsPurged — в монге истекшие сессии убирает TTL индекс с задержкой до минуты,
+// postgres и memory чистят их сами при создании новой сессии
+func expiredSessionsPurged(t *testing.T, s *storage.Storage) {
+	ctx := context.Background()
+	userID := primitive.NewObjectID().Hex()
+	defer s.Sessions.RevokeUserSessions(ctx, userID) //nolint:errcheck
+
+	expired := &models.Session{
+		UserID:    userID,
+		FamilyID:  primitive.NewObjectID().Hex(),
+		TokenHash: "hash-" + primitive.NewObjectID().Hex(),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	if err := s.Sessions.CreateSession(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh := &models.Session{
+		UserID:    userID,
+		FamilyID:  primitive.NewObjectID().Hex(),
+		TokenHash: "hash-" + primitive.NewObjectID().Hex(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := s.Sessions.CreateSession(ctx, fresh); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Sessions.GetSessionByTokenHash(ctx, expired.TokenHash); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expired session is still stored: %v", err)
+	}
+	if _, err := s.Sessions.GetSessionByTokenHash(ctx, fresh.TokenHash); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemoryStorage(t *testing.T) {
+	s := storage.NewMemoryStorage()
+	runConformance(t, s)
+	t.Run("expired sessions", func(t *testing.T) {
+		expiredSessionsPurged(t, s)
+	})
+}
+
+func TestMongoStorage(t *testing.T) {
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("MONGO_URI is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// * Своя база на каждый прогон, индексы создают те же миграции, что и на проде
+	db := client.Database("conformance_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() {
+		db.Drop(context.Background())           //nolint:errcheck
+		client.Disconnect(context.Background()) //nolint:errcheck
+	})
+
+	if _, err := migration.NewMigrator(db, migrations.All).Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Транзакции нужны replica set, на одиночном mongod их не включаем
+	runConformance(t, storage.NewMongoStorage(db, os.Getenv("MONGO_TRANSACTIONS") == "true"))
+}
+
+func TestPostgresStorage(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_DSN is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close() //nolint:errcheck
+	})
+
+	if err := db.PingContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// * Схему создают sql миграции, проверки удаляют свои записи за собой
+	if _, err := migration.NewSQLMigrator(db, migrations.Postgres).Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	s := storage.NewPostgresStorage(db)
+	runConformance(t, s)
+	t.Run("expired sessions", func(t *testing.T) {
+		expiredSessionsPurged(t, s)
+	})
+}